@@ -0,0 +1,369 @@
+package rbtree
+
+import (
+	"sync"
+
+	"golang.org/x/exp/constraints"
+)
+
+// ArenaTree 是节点存放在切片中的红黑树
+// 节点之间用 int32 下标代替指针，颜色存放在 parent 字段的最高位，
+// 整棵树只有一个切片，GC 不需要扫描大量的小对象
+// 下标 0 是叶子节点（相当于 RBTree 的 leaf），删除释放的位置通过空闲链表复用
+type ArenaTree[K constraints.Ordered, V any] struct {
+	mu    sync.RWMutex
+	nodes []arenaNode[K, V]
+	root  int32
+	size  int
+	// 空闲链表头，空闲节点通过 left 串联
+	free int32
+}
+
+type arenaNode[K constraints.Ordered, V any] struct {
+	key   K
+	value V
+	left  int32
+	right int32
+	// 低 31 位是父节点下标，最高位为 1 表示红色
+	parent uint32
+}
+
+const (
+	arenaNil     int32  = 0
+	arenaRedBit  uint32 = 1 << 31
+	arenaIdxMask uint32 = arenaRedBit - 1
+)
+
+func NewArenaTree[K constraints.Ordered, V any](capacity int) *ArenaTree[K, V] {
+	at := new(ArenaTree[K, V])
+	at.nodes = make([]arenaNode[K, V], 1, capacity+1)
+	return at
+}
+
+func (at *ArenaTree[K, V]) Put(key K, value V) {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	at.insert(key, value)
+}
+
+func (at *ArenaTree[K, V]) Get(key K) (value V, ok bool) {
+	at.mu.RLock()
+	defer at.mu.RUnlock()
+	if n := at.search(key); n != arenaNil {
+		return at.nodes[n].value, true
+	}
+	return value, false
+}
+
+func (at *ArenaTree[K, V]) Remove(key K) bool {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	return at.delete(key)
+}
+
+func (at *ArenaTree[K, V]) Len() int {
+	at.mu.RLock()
+	defer at.mu.RUnlock()
+	return at.size
+}
+
+func (at *ArenaTree[K, V]) init() {
+	if at.nodes == nil {
+		at.nodes = make([]arenaNode[K, V], 1)
+	}
+}
+
+func (at *ArenaTree[K, V]) parent(n int32) int32 {
+	return int32(at.nodes[n].parent & arenaIdxMask)
+}
+
+func (at *ArenaTree[K, V]) setParent(n, p int32) {
+	at.nodes[n].parent = at.nodes[n].parent&arenaRedBit | uint32(p)
+}
+
+func (at *ArenaTree[K, V]) isRed(n int32) bool {
+	return at.nodes[n].parent&arenaRedBit != 0
+}
+
+func (at *ArenaTree[K, V]) setColor(n int32, c color) {
+	if c == red {
+		at.nodes[n].parent |= arenaRedBit
+	} else {
+		at.nodes[n].parent &^= arenaRedBit
+	}
+}
+
+func (at *ArenaTree[K, V]) colorOf(n int32) color {
+	if at.isRed(n) {
+		return red
+	}
+	return black
+}
+
+// 优先复用空闲链表中的位置
+func (at *ArenaTree[K, V]) alloc(key K, value V) int32 {
+	n := arenaNode[K, V]{key: key, value: value, parent: arenaRedBit}
+	if at.free != arenaNil {
+		idx := at.free
+		at.free = at.nodes[idx].left
+		at.nodes[idx] = n
+		return idx
+	}
+	if len(at.nodes) > int(arenaIdxMask) {
+		panic("rbtree: arena tree is full")
+	}
+	at.nodes = append(at.nodes, n)
+	return int32(len(at.nodes) - 1)
+}
+
+// 清空节点，避免 key/value 中的指针被继续引用
+func (at *ArenaTree[K, V]) release(n int32) {
+	at.nodes[n] = arenaNode[K, V]{left: at.free}
+	at.free = n
+}
+
+func (at *ArenaTree[K, V]) search(key K) int32 {
+	cur := at.root
+	for cur != arenaNil {
+		n := &at.nodes[cur]
+		if key < n.key {
+			cur = n.left
+		} else if key > n.key {
+			cur = n.right
+		} else {
+			return cur
+		}
+	}
+	return arenaNil
+}
+
+func (at *ArenaTree[K, V]) insert(key K, value V) {
+	at.init()
+	parent, cur := arenaNil, at.root
+	for cur != arenaNil {
+		parent = cur
+		n := &at.nodes[cur]
+		if key < n.key {
+			cur = n.left
+		} else if key > n.key {
+			cur = n.right
+		} else {
+			n.value = value
+			return
+		}
+	}
+	n := at.alloc(key, value)
+	at.setParent(n, parent)
+	if parent == arenaNil {
+		at.root = n
+	} else if key < at.nodes[parent].key {
+		at.nodes[parent].left = n
+	} else {
+		at.nodes[parent].right = n
+	}
+	at.insertAdjust(n)
+	at.size++
+}
+
+// 与 RBTree.insertAdjust 的各种情况相同
+func (at *ArenaTree[K, V]) insertAdjust(n int32) {
+	for n != at.root && at.isRed(at.parent(n)) {
+		p := at.parent(n)
+		gp := at.parent(p)
+		if p == at.nodes[gp].left {
+			u := at.nodes[gp].right
+			// case 2: 父节点和叔父节点都是红色
+			if at.isRed(u) {
+				at.setColor(p, black)
+				at.setColor(u, black)
+				at.setColor(gp, red)
+				n = gp
+				continue
+			}
+			// case 3.1.2: 当前节点是右节点，父节点左旋后变为 case 3.1.1
+			if n == at.nodes[p].right {
+				at.leftRotate(p)
+				n, p = p, n
+			}
+			// case 3.1.1
+			at.setColor(p, black)
+			at.setColor(gp, red)
+			at.rightRotate(gp)
+		} else {
+			u := at.nodes[gp].left
+			if at.isRed(u) {
+				at.setColor(p, black)
+				at.setColor(u, black)
+				at.setColor(gp, red)
+				n = gp
+				continue
+			}
+			// case 3.2.2
+			if n == at.nodes[p].left {
+				at.rightRotate(p)
+				n, p = p, n
+			}
+			// case 3.2.1
+			at.setColor(p, black)
+			at.setColor(gp, red)
+			at.leftRotate(gp)
+		}
+	}
+	at.setColor(at.root, black)
+}
+
+func (at *ArenaTree[K, V]) leftRotate(n int32) {
+	right := at.nodes[n].right
+	at.nodes[n].right = at.nodes[right].left
+	if at.nodes[right].left != arenaNil {
+		at.setParent(at.nodes[right].left, n)
+	}
+	p := at.parent(n)
+	at.setParent(right, p)
+	if p == arenaNil {
+		at.root = right
+	} else if n == at.nodes[p].left {
+		at.nodes[p].left = right
+	} else {
+		at.nodes[p].right = right
+	}
+	at.nodes[right].left = n
+	at.setParent(n, right)
+}
+
+func (at *ArenaTree[K, V]) rightRotate(n int32) {
+	left := at.nodes[n].left
+	at.nodes[n].left = at.nodes[left].right
+	if at.nodes[left].right != arenaNil {
+		at.setParent(at.nodes[left].right, n)
+	}
+	p := at.parent(n)
+	at.setParent(left, p)
+	if p == arenaNil {
+		at.root = left
+	} else if n == at.nodes[p].right {
+		at.nodes[p].right = left
+	} else {
+		at.nodes[p].left = left
+	}
+	at.nodes[left].right = n
+	at.setParent(n, left)
+}
+
+// 用 b 替换 a 的位置，b 可以是叶子节点
+func (at *ArenaTree[K, V]) exchange(a, b int32) {
+	p := at.parent(a)
+	if p == arenaNil {
+		at.root = b
+	} else if a == at.nodes[p].left {
+		at.nodes[p].left = b
+	} else {
+		at.nodes[p].right = b
+	}
+	at.setParent(b, p)
+}
+
+func (at *ArenaTree[K, V]) delete(key K) bool {
+	target := at.search(key)
+	if target == arenaNil {
+		return false
+	}
+
+	removedColor := at.colorOf(target)
+	var x int32
+	if at.nodes[target].left == arenaNil {
+		x = at.nodes[target].right
+		at.exchange(target, x)
+	} else if at.nodes[target].right == arenaNil {
+		x = at.nodes[target].left
+		at.exchange(target, x)
+	} else {
+		// 用前驱节点替换被删除节点
+		s := at.nodes[target].left
+		for at.nodes[s].right != arenaNil {
+			s = at.nodes[s].right
+		}
+		removedColor = at.colorOf(s)
+		x = at.nodes[s].left
+		if at.parent(s) == target {
+			at.setParent(x, s)
+		} else {
+			at.exchange(s, x)
+			at.nodes[s].left = at.nodes[target].left
+			at.setParent(at.nodes[s].left, s)
+		}
+		at.exchange(target, s)
+		at.nodes[s].right = at.nodes[target].right
+		at.setParent(at.nodes[s].right, s)
+		at.setColor(s, at.colorOf(target))
+	}
+	if removedColor == black {
+		at.deleteAdjust(x)
+	}
+	// 叶子节点的父节点只在调整时使用
+	at.nodes[arenaNil] = arenaNode[K, V]{}
+	at.release(target)
+	at.size--
+	return true
+}
+
+// 与 RBTree.deleteAdjust 的各种情况相同
+func (at *ArenaTree[K, V]) deleteAdjust(n int32) {
+	for n != at.root && !at.isRed(n) {
+		p := at.parent(n)
+		if n == at.nodes[p].left {
+			s := at.nodes[p].right
+			// case 2: 兄弟节点是红色
+			if at.isRed(s) {
+				at.setColor(s, black)
+				at.setColor(p, red)
+				at.leftRotate(p)
+				s = at.nodes[p].right
+			}
+			// case 1.1: 兄弟节点的子节点都是黑色
+			if !at.isRed(at.nodes[s].left) && !at.isRed(at.nodes[s].right) {
+				at.setColor(s, red)
+				n = p
+				continue
+			}
+			// case 1.3: 兄弟节点的右子节点是黑色，左子节点是红色
+			if !at.isRed(at.nodes[s].right) {
+				at.setColor(at.nodes[s].left, black)
+				at.setColor(s, red)
+				at.rightRotate(s)
+				s = at.nodes[p].right
+			}
+			// case 1.2: 兄弟节点的右子节点是红色
+			at.setColor(s, at.colorOf(p))
+			at.setColor(p, black)
+			at.setColor(at.nodes[s].right, black)
+			at.leftRotate(p)
+			n = at.root
+		} else {
+			s := at.nodes[p].left
+			if at.isRed(s) {
+				at.setColor(s, black)
+				at.setColor(p, red)
+				at.rightRotate(p)
+				s = at.nodes[p].left
+			}
+			if !at.isRed(at.nodes[s].left) && !at.isRed(at.nodes[s].right) {
+				at.setColor(s, red)
+				n = p
+				continue
+			}
+			if !at.isRed(at.nodes[s].left) {
+				at.setColor(at.nodes[s].right, black)
+				at.setColor(s, red)
+				at.leftRotate(s)
+				s = at.nodes[p].left
+			}
+			at.setColor(s, at.colorOf(p))
+			at.setColor(p, black)
+			at.setColor(at.nodes[s].left, black)
+			at.rightRotate(p)
+			n = at.root
+		}
+	}
+	at.setColor(n, black)
+}
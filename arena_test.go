@@ -0,0 +1,169 @@
+package rbtree
+
+import (
+	"math/rand"
+	"runtime"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/constraints"
+)
+
+// 检查 ArenaTree 的红黑树性质，返回节点数
+func checkArenaTree[K constraints.Ordered, V any](t *testing.T, at *ArenaTree[K, V]) int {
+	t.Helper()
+	if at.root == arenaNil {
+		return 0
+	}
+	if at.isRed(at.root) || at.parent(at.root) != arenaNil {
+		t.Fatal("error: arena tree root should black and has no parent")
+	}
+	var walk func(n int32) (int, int)
+	walk = func(n int32) (int, int) {
+		if n == arenaNil {
+			return 1, 0
+		}
+		l, r := at.nodes[n].left, at.nodes[n].right
+		if l != arenaNil && (at.parent(l) != n || at.nodes[l].key >= at.nodes[n].key) {
+			t.Fatalf("error: bad left child of %v", at.nodes[n].key)
+		}
+		if r != arenaNil && (at.parent(r) != n || at.nodes[r].key <= at.nodes[n].key) {
+			t.Fatalf("error: bad right child of %v", at.nodes[n].key)
+		}
+		if at.isRed(n) && (at.isRed(l) || at.isRed(r)) {
+			t.Fatalf("error: red node %v has red child", at.nodes[n].key)
+		}
+		lb, lc := walk(l)
+		rb, rc := walk(r)
+		if lb != rb {
+			t.Fatalf("error: black height of %v not equal", at.nodes[n].key)
+		}
+		if !at.isRed(n) {
+			lb++
+		}
+		return lb, lc + rc + 1
+	}
+	_, cnt := walk(at.root)
+	if cnt != at.size {
+		t.Fatalf("error: arena tree size should %v, but get %v", cnt, at.size)
+	}
+	return cnt
+}
+
+func TestArenaTreeRandomPutAndRemove(t *testing.T) {
+	for seed := int64(0); seed < 100; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		at := NewArenaTree[int, int](0)
+		m := make(map[int]int)
+		for i := 0; i < 500; i++ {
+			k := r.Intn(100)
+			if r.Intn(2) == 0 {
+				at.Put(k, i)
+				m[k] = i
+			} else {
+				_, ok := m[k]
+				if at.Remove(k) != ok {
+					t.Fatalf("error: remove %v should return %v", k, ok)
+				}
+				delete(m, k)
+			}
+			if checkArenaTree(t, at) != len(m) {
+				t.Fatalf("error: arena tree size should %v", len(m))
+			}
+		}
+		for k, v := range m {
+			if value, ok := at.Get(k); !ok || value != v {
+				t.Fatalf("error: get %v should %v, but get %v", k, v, value)
+			}
+		}
+	}
+}
+
+func TestArenaTreeReuse(t *testing.T) {
+	var at ArenaTree[int, int]
+	for i := 0; i < 100; i++ {
+		at.Put(i, i)
+	}
+	for i := 0; i < 50; i++ {
+		at.Remove(i)
+	}
+	for i := 100; i < 150; i++ {
+		at.Put(i, i)
+	}
+	if len(at.nodes) != 101 {
+		t.Fatalf("error: freed slots should reused, but nodes length is %v", len(at.nodes))
+	}
+	if checkArenaTree(t, &at) != 100 || at.Len() != 100 {
+		t.Fatal("error: arena tree size should 100")
+	}
+}
+
+const benchTreeSize = 1 << 20
+
+func BenchmarkPointerTreePut(b *testing.B) {
+	b.ReportAllocs()
+	rbt := NewRBTree[int, int]()
+	for i := 0; i < b.N; i++ {
+		rbt.Put(i%benchTreeSize, i)
+	}
+}
+
+func BenchmarkArenaTreePut(b *testing.B) {
+	b.ReportAllocs()
+	at := NewArenaTree[int, int](0)
+	for i := 0; i < b.N; i++ {
+		at.Put(i%benchTreeSize, i)
+	}
+}
+
+func BenchmarkPointerTreePutRemove(b *testing.B) {
+	b.ReportAllocs()
+	rbt := NewRBTree[int, int]()
+	for i := 0; i < b.N; i++ {
+		rbt.Put(i, i)
+		if i >= 1024 {
+			rbt.Remove(i - 1024)
+		}
+	}
+}
+
+func BenchmarkArenaTreePutRemove(b *testing.B) {
+	b.ReportAllocs()
+	at := NewArenaTree[int, int](1024)
+	for i := 0; i < b.N; i++ {
+		at.Put(i, i)
+		if i >= 1024 {
+			at.Remove(i - 1024)
+		}
+	}
+}
+
+// 测量树中有 benchTreeSize 个节点时一次完整 GC 的耗时
+func benchmarkGC(b *testing.B, put func(k, v int)) {
+	for i := 0; i < benchTreeSize; i++ {
+		put(i, i)
+	}
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		runtime.GC()
+	}
+	b.StopTimer()
+	runtime.ReadMemStats(&after)
+	pause := time.Duration(after.PauseTotalNs - before.PauseTotalNs)
+	b.ReportMetric(float64(pause.Nanoseconds())/float64(b.N), "pause-ns/gc")
+}
+
+func BenchmarkPointerTreeGC(b *testing.B) {
+	rbt := NewRBTree[int, int]()
+	benchmarkGC(b, rbt.Put)
+	runtime.KeepAlive(rbt)
+}
+
+func BenchmarkArenaTreeGC(b *testing.B) {
+	at := NewArenaTree[int, int](benchTreeSize)
+	benchmarkGC(b, at.Put)
+	runtime.KeepAlive(at)
+}
@@ -0,0 +1,124 @@
+package rbtree
+
+import "golang.org/x/exp/constraints"
+
+// Aggregator 定义子树附加信息的计算方式，(A, Combine, Identity) 需要构成幺半群：
+// Combine 满足结合律，Identity 是单位元；Combine 不要求满足交换律，
+// 参数总是按 key 升序传入
+type Aggregator[K any, V any, A any] interface {
+	Identity() A
+	Combine(a, b A) A
+	FromEntry(key K, value V) A
+}
+
+type augmentedEntry[V any, A any] struct {
+	value V
+	// 整棵子树的聚合结果
+	agg A
+}
+
+// AugmentedTree 是每个节点都记录子树聚合结果的红黑树，
+// 聚合结果在插入、删除和旋转时维护，可以在 O(log n) 时间内计算任意 key 范围的聚合结果
+type AugmentedTree[K constraints.Ordered, V any, A any] struct {
+	tree *RBTree[K, *augmentedEntry[V, A]]
+	agg  Aggregator[K, V, A]
+}
+
+func NewAugmentedTree[K constraints.Ordered, V any, A any](agg Aggregator[K, V, A]) *AugmentedTree[K, V, A] {
+	at := &AugmentedTree[K, V, A]{
+		tree: NewRBTree[K, *augmentedEntry[V, A]](),
+		agg:  agg,
+	}
+	at.tree.augment = func(n *node[K, *augmentedEntry[V, A]]) {
+		e := n.value
+		e.agg = agg.Combine(agg.Combine(at.subtree(n.left), agg.FromEntry(n.key, e.value)), at.subtree(n.right))
+	}
+	return at
+}
+
+func (at *AugmentedTree[K, V, A]) subtree(n *node[K, *augmentedEntry[V, A]]) A {
+	if n == nil || n == at.tree.leaf {
+		return at.agg.Identity()
+	}
+	return n.value.agg
+}
+
+func (at *AugmentedTree[K, V, A]) Put(key K, value V) {
+	at.tree.Put(key, &augmentedEntry[V, A]{value: value})
+}
+
+func (at *AugmentedTree[K, V, A]) Get(key K) (value V, ok bool) {
+	e, ok := at.tree.Get(key)
+	if !ok {
+		return value, false
+	}
+	return e.value, true
+}
+
+func (at *AugmentedTree[K, V, A]) Remove(key K) bool {
+	return at.tree.Remove(key)
+}
+
+func (at *AugmentedTree[K, V, A]) Len() int {
+	at.tree.mu.RLock()
+	defer at.tree.mu.RUnlock()
+	return at.tree.size
+}
+
+// AggregateAll 返回所有节点的聚合结果，O(1)
+func (at *AugmentedTree[K, V, A]) AggregateAll() A {
+	at.tree.mu.RLock()
+	defer at.tree.mu.RUnlock()
+	return at.subtree(at.tree.root)
+}
+
+// Aggregate 返回 key 在 [lo, hi] 范围内的节点的聚合结果，O(log n)
+func (at *AugmentedTree[K, V, A]) Aggregate(lo, hi K) A {
+	at.tree.mu.RLock()
+	defer at.tree.mu.RUnlock()
+	if lo > hi {
+		return at.agg.Identity()
+	}
+	return at.aggregate(at.tree.root, lo, hi)
+}
+
+// 先找到 key 落在 [lo, hi] 中的最高节点，
+// 再分别沿左子树计算 >= lo 的部分，沿右子树计算 <= hi 的部分
+func (at *AugmentedTree[K, V, A]) aggregate(n *node[K, *augmentedEntry[V, A]], lo, hi K) A {
+	for n != nil && n != at.tree.leaf {
+		if n.key < lo {
+			n = n.right
+		} else if n.key > hi {
+			n = n.left
+		} else {
+			left := at.suffix(n.left, lo)
+			right := at.prefix(n.right, hi)
+			return at.agg.Combine(at.agg.Combine(left, at.agg.FromEntry(n.key, n.value.value)), right)
+		}
+	}
+	return at.agg.Identity()
+}
+
+// 子树中 key >= lo 的节点的聚合结果
+func (at *AugmentedTree[K, V, A]) suffix(n *node[K, *augmentedEntry[V, A]], lo K) A {
+	if n == at.tree.leaf {
+		return at.agg.Identity()
+	}
+	if n.key < lo {
+		return at.suffix(n.right, lo)
+	}
+	cur := at.agg.Combine(at.agg.FromEntry(n.key, n.value.value), at.subtree(n.right))
+	return at.agg.Combine(at.suffix(n.left, lo), cur)
+}
+
+// 子树中 key <= hi 的节点的聚合结果
+func (at *AugmentedTree[K, V, A]) prefix(n *node[K, *augmentedEntry[V, A]], hi K) A {
+	if n == at.tree.leaf {
+		return at.agg.Identity()
+	}
+	if n.key > hi {
+		return at.prefix(n.left, hi)
+	}
+	cur := at.agg.Combine(at.subtree(n.left), at.agg.FromEntry(n.key, n.value.value))
+	return at.agg.Combine(cur, at.prefix(n.right, hi))
+}
@@ -0,0 +1,113 @@
+package rbtree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+type sumAggregator struct{}
+
+func (sumAggregator) Identity() int          { return 0 }
+func (sumAggregator) Combine(a, b int) int   { return a + b }
+func (sumAggregator) FromEntry(_, v int) int { return v }
+
+// 不满足交换律，用于检查聚合顺序
+type concatAggregator struct{}
+
+func (concatAggregator) Identity() string                 { return "" }
+func (concatAggregator) Combine(a, b string) string       { return a + b }
+func (concatAggregator) FromEntry(_ int, v string) string { return v }
+
+type minMax struct {
+	min, max int
+	empty    bool
+}
+
+type minMaxAggregator struct{}
+
+func (minMaxAggregator) Identity() minMax { return minMax{empty: true} }
+func (minMaxAggregator) Combine(a, b minMax) minMax {
+	if a.empty {
+		return b
+	}
+	if b.empty {
+		return a
+	}
+	return minMax{min: min(a.min, b.min), max: max(a.max, b.max)}
+}
+func (minMaxAggregator) FromEntry(_, v int) minMax { return minMax{min: v, max: v} }
+
+func TestAugmentedTreeSum(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	at := NewAugmentedTree[int, int, int](sumAggregator{})
+	m := make(map[int]int)
+	for i := 0; i < 3000; i++ {
+		k := r.Intn(500)
+		if r.Intn(3) == 0 {
+			at.Remove(k)
+			delete(m, k)
+		} else {
+			v := r.Intn(100)
+			at.Put(k, v)
+			m[k] = v
+		}
+		if i%100 != 0 {
+			continue
+		}
+		checkRBTree(t, at.tree)
+		lo := r.Intn(520) - 10
+		hi := lo + r.Intn(200)
+		want := 0
+		for k, v := range m {
+			if k >= lo && k <= hi {
+				want += v
+			}
+		}
+		if got := at.Aggregate(lo, hi); got != want {
+			t.Fatalf("error: sum of [%v, %v] should %v, but get %v", lo, hi, want, got)
+		}
+	}
+	total := 0
+	for _, v := range m {
+		total += v
+	}
+	if at.AggregateAll() != total || at.Len() != len(m) {
+		t.Fatalf("error: sum should %v, but get %v", total, at.AggregateAll())
+	}
+}
+
+func TestAugmentedTreeOrder(t *testing.T) {
+	at := NewAugmentedTree[int, string, string](concatAggregator{})
+	for _, k := range []int{5, 3, 8, 1, 4, 7, 9, 2, 6, 0} {
+		at.Put(k, string(rune('a'+k)))
+	}
+	at.Remove(4)
+	if got := at.AggregateAll(); got != "abcdfghij" {
+		t.Fatalf("error: aggregate should abcdfghij, but get %v", got)
+	}
+	if got := at.Aggregate(2, 7); got != "cdfgh" {
+		t.Fatalf("error: aggregate [2, 7] should cdfgh, but get %v", got)
+	}
+	if got := at.Aggregate(7, 2); got != "" {
+		t.Fatalf("error: aggregate [7, 2] should empty, but get %v", got)
+	}
+}
+
+func TestAugmentedTreeMinMax(t *testing.T) {
+	at := NewAugmentedTree[int, int, minMax](minMaxAggregator{})
+	for i := 0; i < 100; i++ {
+		at.Put(i, (i*37)%101)
+	}
+	got := at.Aggregate(10, 20)
+	want := minMax{min: 100, max: 0}
+	for i := 10; i <= 20; i++ {
+		want.min = min(want.min, (i*37)%101)
+		want.max = max(want.max, (i*37)%101)
+	}
+	if got != want {
+		t.Fatalf("error: aggregate [10, 20] should %v, but get %v", want, got)
+	}
+	if v, ok := at.Get(3); !ok || v != 10 {
+		t.Fatalf("error: get 3 should 10, but get %v", v)
+	}
+}
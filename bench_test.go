@@ -0,0 +1,561 @@
+package rbtree
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"golang.org/x/exp/constraints"
+)
+
+// 参与对比的有序/无序映射
+type benchMap[K constraints.Ordered] interface {
+	Put(key K, value int)
+	Get(key K) (int, bool)
+	Remove(key K) bool
+	// 从 lo 开始升序访问 n 个 key
+	Scan(lo K, n int, fn func(K, int))
+}
+
+// RBTree 本身已经加锁
+type rbtreeMap[K constraints.Ordered] struct {
+	*RBTree[K, int]
+}
+
+func (m rbtreeMap[K]) Scan(lo K, n int, fn func(K, int)) {
+	m.RBTree.Tail(lo).Ascend(func(k K, v int) bool {
+		fn(k, v)
+		n--
+		return n > 0
+	})
+}
+
+// map + 扫描时排序
+type sortedMap[K constraints.Ordered] struct {
+	mu sync.RWMutex
+	m  map[K]int
+}
+
+func (m *sortedMap[K]) Put(key K, value int) {
+	m.mu.Lock()
+	m.m[key] = value
+	m.mu.Unlock()
+}
+
+func (m *sortedMap[K]) Get(key K) (int, bool) {
+	m.mu.RLock()
+	v, ok := m.m[key]
+	m.mu.RUnlock()
+	return v, ok
+}
+
+func (m *sortedMap[K]) Remove(key K) bool {
+	m.mu.Lock()
+	_, ok := m.m[key]
+	delete(m.m, key)
+	m.mu.Unlock()
+	return ok
+}
+
+func (m *sortedMap[K]) Scan(lo K, n int, fn func(K, int)) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := make([]K, 0, len(m.m))
+	for k := range m.m {
+		if k >= lo {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	for i := 0; i < n && i < len(keys); i++ {
+		fn(keys[i], m.m[keys[i]])
+	}
+}
+
+// sync.Map 无序，只参与点查询的对比
+type syncMap[K constraints.Ordered] struct {
+	m sync.Map
+}
+
+func (m *syncMap[K]) Put(key K, value int) {
+	m.m.Store(key, value)
+}
+
+func (m *syncMap[K]) Get(key K) (int, bool) {
+	v, ok := m.m.Load(key)
+	if !ok {
+		return 0, false
+	}
+	return v.(int), true
+}
+
+func (m *syncMap[K]) Remove(key K) bool {
+	_, ok := m.m.LoadAndDelete(key)
+	return ok
+}
+
+func (m *syncMap[K]) Scan(K, int, func(K, int)) {
+	panic("sync.Map is unordered")
+}
+
+// 类似 google/btree 的 B 树，每个节点最多 2*btreeDegree-1 个 key
+const btreeDegree = 32
+
+type btreeNode[K constraints.Ordered] struct {
+	keys     []K
+	values   []int
+	children []*btreeNode[K]
+}
+
+func (n *btreeNode[K]) leaf() bool {
+	return len(n.children) == 0
+}
+
+// 第一个大于等于 key 的位置
+func (n *btreeNode[K]) find(key K) (int, bool) {
+	i := sort.Search(len(n.keys), func(i int) bool { return n.keys[i] >= key })
+	return i, i < len(n.keys) && n.keys[i] == key
+}
+
+type btree[K constraints.Ordered] struct {
+	mu   sync.RWMutex
+	root *btreeNode[K]
+	size int
+}
+
+func newBTree[K constraints.Ordered]() *btree[K] {
+	return &btree[K]{root: &btreeNode[K]{}}
+}
+
+func (t *btree[K]) Get(key K) (int, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	n := t.root
+	for {
+		i, ok := n.find(key)
+		if ok {
+			return n.values[i], true
+		}
+		if n.leaf() {
+			return 0, false
+		}
+		n = n.children[i]
+	}
+}
+
+func (t *btree[K]) Put(key K, value int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.root.keys) == 2*btreeDegree-1 {
+		root := &btreeNode[K]{children: []*btreeNode[K]{t.root}}
+		root.split(0)
+		t.root = root
+	}
+	if t.root.insert(key, value) {
+		t.size++
+	}
+}
+
+// 把已满的第 i 个子节点分裂成两个，中间的 key 上移
+func (n *btreeNode[K]) split(i int) {
+	child := n.children[i]
+	mid := btreeDegree - 1
+	right := &btreeNode[K]{
+		keys:   append([]K(nil), child.keys[mid+1:]...),
+		values: append([]int(nil), child.values[mid+1:]...),
+	}
+	if !child.leaf() {
+		right.children = append([]*btreeNode[K](nil), child.children[mid+1:]...)
+		child.children = child.children[:mid+1]
+	}
+	key, value := child.keys[mid], child.values[mid]
+	child.keys, child.values = child.keys[:mid], child.values[:mid]
+
+	n.keys = append(n.keys, key)
+	copy(n.keys[i+1:], n.keys[i:])
+	n.keys[i] = key
+	n.values = append(n.values, value)
+	copy(n.values[i+1:], n.values[i:])
+	n.values[i] = value
+	n.children = append(n.children, nil)
+	copy(n.children[i+2:], n.children[i+1:])
+	n.children[i+1] = right
+}
+
+// 节点未满，返回是否插入了新的 key
+func (n *btreeNode[K]) insert(key K, value int) bool {
+	i, ok := n.find(key)
+	if ok {
+		n.values[i] = value
+		return false
+	}
+	if n.leaf() {
+		n.keys = append(n.keys, key)
+		copy(n.keys[i+1:], n.keys[i:])
+		n.keys[i] = key
+		n.values = append(n.values, value)
+		copy(n.values[i+1:], n.values[i:])
+		n.values[i] = value
+		return true
+	}
+	if len(n.children[i].keys) == 2*btreeDegree-1 {
+		n.split(i)
+		if key == n.keys[i] {
+			n.values[i] = value
+			return false
+		}
+		if key > n.keys[i] {
+			i++
+		}
+	}
+	return n.children[i].insert(key, value)
+}
+
+func (t *btree[K]) Remove(key K) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ok := t.root.remove(key)
+	if len(t.root.keys) == 0 && !t.root.leaf() {
+		t.root = t.root.children[0]
+	}
+	if ok {
+		t.size--
+	}
+	return ok
+}
+
+func (n *btreeNode[K]) removeAt(i int) {
+	n.keys = append(n.keys[:i], n.keys[i+1:]...)
+	n.values = append(n.values[:i], n.values[i+1:]...)
+}
+
+// 合并第 i、i+1 个子节点和它们之间的 key
+func (n *btreeNode[K]) merge(i int) {
+	left, right := n.children[i], n.children[i+1]
+	left.keys = append(append(left.keys, n.keys[i]), right.keys...)
+	left.values = append(append(left.values, n.values[i]), right.values...)
+	left.children = append(left.children, right.children...)
+	n.removeAt(i)
+	n.children = append(n.children[:i+1], n.children[i+2:]...)
+}
+
+// 保证第 i 个子节点至少有 btreeDegree 个 key，返回 key 所在的子节点下标
+func (n *btreeNode[K]) grow(i int) int {
+	child := n.children[i]
+	if i > 0 && len(n.children[i-1].keys) >= btreeDegree {
+		// 从左兄弟借一个 key
+		left := n.children[i-1]
+		last := len(left.keys) - 1
+		child.keys = append([]K{n.keys[i-1]}, child.keys...)
+		child.values = append([]int{n.values[i-1]}, child.values...)
+		n.keys[i-1], n.values[i-1] = left.keys[last], left.values[last]
+		left.keys, left.values = left.keys[:last], left.values[:last]
+		if !left.leaf() {
+			child.children = append([]*btreeNode[K]{left.children[last+1]}, child.children...)
+			left.children = left.children[:last+1]
+		}
+		return i
+	}
+	if i < len(n.children)-1 && len(n.children[i+1].keys) >= btreeDegree {
+		// 从右兄弟借一个 key
+		right := n.children[i+1]
+		child.keys = append(child.keys, n.keys[i])
+		child.values = append(child.values, n.values[i])
+		n.keys[i], n.values[i] = right.keys[0], right.values[0]
+		right.removeAt(0)
+		if !right.leaf() {
+			child.children = append(child.children, right.children[0])
+			right.children = right.children[1:]
+		}
+		return i
+	}
+	if i == len(n.children)-1 {
+		i--
+	}
+	n.merge(i)
+	return i
+}
+
+func (n *btreeNode[K]) remove(key K) bool {
+	i, ok := n.find(key)
+	if n.leaf() {
+		if ok {
+			n.removeAt(i)
+		}
+		return ok
+	}
+	if ok {
+		if len(n.children[i].keys) >= btreeDegree {
+			// 用前驱替换
+			p := n.children[i]
+			for !p.leaf() {
+				p = p.children[len(p.children)-1]
+			}
+			last := len(p.keys) - 1
+			n.keys[i], n.values[i] = p.keys[last], p.values[last]
+			return n.children[i].remove(n.keys[i])
+		}
+		if len(n.children[i+1].keys) >= btreeDegree {
+			// 用后继替换
+			s := n.children[i+1]
+			for !s.leaf() {
+				s = s.children[0]
+			}
+			n.keys[i], n.values[i] = s.keys[0], s.values[0]
+			return n.children[i+1].remove(n.keys[i])
+		}
+		n.merge(i)
+		return n.children[i].remove(key)
+	}
+	if len(n.children[i].keys) < btreeDegree {
+		i = n.grow(i)
+	}
+	return n.children[i].remove(key)
+}
+
+func (t *btree[K]) Scan(lo K, cnt int, fn func(K, int)) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var scan func(n *btreeNode[K]) bool
+	scan = func(n *btreeNode[K]) bool {
+		i, _ := n.find(lo)
+		for ; i <= len(n.keys); i++ {
+			if !n.leaf() && !scan(n.children[i]) {
+				return false
+			}
+			if i == len(n.keys) {
+				break
+			}
+			fn(n.keys[i], n.values[i])
+			if cnt--; cnt == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	scan(t.root)
+}
+
+func TestBenchBTree(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	bt := newBTree[int]()
+	m := make(map[int]int)
+	for i := 0; i < 50000; i++ {
+		k := r.Intn(5000)
+		if r.Intn(2) == 0 {
+			bt.Put(k, i)
+			m[k] = i
+		} else {
+			_, ok := m[k]
+			if bt.Remove(k) != ok {
+				t.Fatalf("error: btree remove %v should return %v", k, ok)
+			}
+			delete(m, k)
+		}
+	}
+	if bt.size != len(m) {
+		t.Fatalf("error: btree size should %v, but get %v", len(m), bt.size)
+	}
+	for k, v := range m {
+		if got, ok := bt.Get(k); !ok || got != v {
+			t.Fatalf("error: btree get %v should %v, but get %v", k, v, got)
+		}
+	}
+	prev, cnt := -1, 0
+	bt.Scan(100, 200, func(k, _ int) {
+		if k < 100 || k <= prev {
+			t.Fatalf("error: btree scan get %v after %v", k, prev)
+		}
+		prev = k
+		cnt++
+	})
+	if cnt != 200 {
+		t.Fatalf("error: btree scan should visit 200 keys, but get %v", cnt)
+	}
+}
+
+const benchKeys = 1 << 16
+
+type benchImpl[K constraints.Ordered] struct {
+	name    string
+	new     func() benchMap[K]
+	ordered bool
+}
+
+func benchImpls[K constraints.Ordered]() []benchImpl[K] {
+	return []benchImpl[K]{
+		{"rbtree", func() benchMap[K] { return rbtreeMap[K]{NewRBTree[K, int]()} }, true},
+		{"btree", func() benchMap[K] { return newBTree[K]() }, true},
+		{"map+sort", func() benchMap[K] { return &sortedMap[K]{m: make(map[K]int)} }, true},
+		{"sync.Map", func() benchMap[K] { return &syncMap[K]{} }, false},
+	}
+}
+
+// 各种访问模式的 key 序列
+func benchWorkloads() map[string][]int {
+	r := rand.New(rand.NewSource(1))
+	seq := make([]int, benchKeys)
+	for i := range seq {
+		seq[i] = i
+	}
+	zipf := make([]int, benchKeys)
+	z := rand.NewZipf(r, 1.1, 1, benchKeys-1)
+	for i := range zipf {
+		zipf[i] = int(z.Uint64())
+	}
+	return map[string][]int{
+		"sequential": seq,
+		"random":     r.Perm(benchKeys),
+		"zipf":       zipf,
+	}
+}
+
+var workloadNames = []string{"sequential", "random", "zipf"}
+
+func BenchmarkPut(b *testing.B) {
+	workloads := benchWorkloads()
+	for _, w := range workloadNames {
+		keys := workloads[w]
+		for _, impl := range benchImpls[int]() {
+			b.Run(w+"/"+impl.name, func(b *testing.B) {
+				b.ReportAllocs()
+				m := impl.new()
+				for i := 0; i < b.N; i++ {
+					m.Put(keys[i%benchKeys], i)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	workloads := benchWorkloads()
+	for _, w := range workloadNames {
+		keys := workloads[w]
+		for _, impl := range benchImpls[int]() {
+			b.Run(w+"/"+impl.name, func(b *testing.B) {
+				m := impl.new()
+				for i := 0; i < benchKeys; i++ {
+					m.Put(i, i)
+				}
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					m.Get(keys[i%benchKeys])
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkRemove(b *testing.B) {
+	workloads := benchWorkloads()
+	for _, w := range workloadNames {
+		keys := workloads[w]
+		for _, impl := range benchImpls[int]() {
+			b.Run(w+"/"+impl.name, func(b *testing.B) {
+				m := impl.new()
+				for i := 0; i < benchKeys; i++ {
+					m.Put(i, i)
+				}
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					// 删除后立即放回，保证树的大小不变
+					k := keys[i%benchKeys]
+					if m.Remove(k) {
+						b.StopTimer()
+						m.Put(k, i)
+						b.StartTimer()
+					}
+				}
+			})
+		}
+	}
+}
+
+// 多个 goroutine 同时读写，90% 读
+func BenchmarkParallelMixed(b *testing.B) {
+	for _, impl := range benchImpls[int]() {
+		b.Run(impl.name, func(b *testing.B) {
+			m := impl.new()
+			for i := 0; i < benchKeys; i++ {
+				m.Put(i, i)
+			}
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				r := rand.New(rand.NewSource(rand.Int63()))
+				for pb.Next() {
+					k := r.Intn(benchKeys)
+					if r.Intn(10) == 0 {
+						m.Put(k, k)
+					} else {
+						m.Get(k)
+					}
+				}
+			})
+		})
+	}
+}
+
+// 从随机位置开始升序扫描 100 个 key
+func BenchmarkRangeScan(b *testing.B) {
+	for _, impl := range benchImpls[int]() {
+		if !impl.ordered {
+			continue
+		}
+		b.Run(impl.name, func(b *testing.B) {
+			m := impl.new()
+			for i := 0; i < benchKeys; i++ {
+				m.Put(i, i)
+			}
+			r := rand.New(rand.NewSource(1))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sum := 0
+				m.Scan(r.Intn(benchKeys), 100, func(_, v int) { sum += v })
+			}
+		})
+	}
+}
+
+// string key 与 int key 的对比
+func BenchmarkKeyType(b *testing.B) {
+	ints := rand.New(rand.NewSource(1)).Perm(benchKeys)
+	strs := make([]string, len(ints))
+	for i, k := range ints {
+		strs[i] = "key-" + strconv.Itoa(k)
+	}
+	b.Run("int/put", func(b *testing.B) {
+		rbt := NewRBTree[int, int]()
+		for i := 0; i < b.N; i++ {
+			rbt.Put(ints[i%benchKeys], i)
+		}
+	})
+	b.Run("string/put", func(b *testing.B) {
+		rbt := NewRBTree[string, int]()
+		for i := 0; i < b.N; i++ {
+			rbt.Put(strs[i%benchKeys], i)
+		}
+	})
+	b.Run("int/get", func(b *testing.B) {
+		rbt := NewRBTree[int, int]()
+		for i, k := range ints {
+			rbt.Put(k, i)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			rbt.Get(ints[i%benchKeys])
+		}
+	})
+	b.Run("string/get", func(b *testing.B) {
+		rbt := NewRBTree[string, int]()
+		for i, k := range strs {
+			rbt.Put(k, i)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			rbt.Get(strs[i%benchKeys])
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package rbtree
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// 二进制格式：
+// magic "RBT" | version(1 byte) | count(uvarint) | count 个按 key 升序排列的 (key, value)
+// key、value 的编码由 SetCodec 设置的 Codec 决定，默认为 DefaultCodec
+const binaryVersion = 1
+
+var (
+	binaryMagic = []byte("RBT")
+
+	ErrInvalidData = errors.New("rbtree: invalid binary data")
+	ErrUnsorted    = errors.New("rbtree: keys are not strictly ascending")
+)
+
+// SetCodec 设置 MarshalBinary / UnmarshalBinary 使用的编解码器，传 nil 表示使用默认编解码器
+func (rbt *RBTree[K, V]) SetCodec(kc Codec[K], vc Codec[V]) {
+	rbt.mu.Lock()
+	defer rbt.mu.Unlock()
+	rbt.keyCodec = kc
+	rbt.valueCodec = vc
+}
+
+func (rbt *RBTree[K, V]) codecs() (Codec[K], Codec[V]) {
+	kc, vc := rbt.keyCodec, rbt.valueCodec
+	if kc == nil {
+		kc = DefaultCodec[K]()
+	}
+	if vc == nil {
+		vc = DefaultCodec[V]()
+	}
+	return kc, vc
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler
+func (rbt *RBTree[K, V]) MarshalBinary() ([]byte, error) {
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	return rbt.appendBinary(nil)
+}
+
+func (rbt *RBTree[K, V]) appendBinary(buf []byte) (data []byte, err error) {
+	kc, vc := rbt.codecs()
+	buf = append(buf, binaryMagic...)
+	buf = append(buf, binaryVersion)
+	buf = appendUvarint(buf, uint64(rbt.size))
+	rbt.walk(func(n *node[K, V]) bool {
+		if buf, err = kc.Append(buf, n.key); err != nil {
+			return false
+		}
+		buf, err = vc.Append(buf, n.value)
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler
+// 数据中的记录已按 key 升序排列，直接在 O(n) 时间内重建红黑树，原有内容被替换
+// 重建不会向 watcher 发送事件
+func (rbt *RBTree[K, V]) UnmarshalBinary(data []byte) error {
+	rbt.mu.Lock()
+	defer rbt.mu.Unlock()
+	keys, values, err := rbt.decodeBinary(data)
+	if err != nil {
+		return err
+	}
+	rbt.build(keys, values)
+	return nil
+}
+
+func (rbt *RBTree[K, V]) decodeBinary(data []byte) ([]K, []V, error) {
+	if len(data) < len(binaryMagic)+1 || string(data[:len(binaryMagic)]) != string(binaryMagic) {
+		return nil, nil, ErrInvalidData
+	}
+	data = data[len(binaryMagic):]
+	if data[0] != binaryVersion {
+		return nil, nil, errors.New("rbtree: unsupported binary version")
+	}
+	data = data[1:]
+	count, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, nil, ErrInvalidData
+	}
+	data = data[n:]
+
+	kc, vc := rbt.codecs()
+	// 自定义的 Codec 可能把记录编码为 0 或 1 个字节，不能根据数据长度拒绝 count，
+	// 只限制预分配的容量，防止异常的 count 导致分配过大的内存；数据不完整时由解码发现
+	capacity := count
+	if capacity > uint64(len(data)) {
+		capacity = uint64(len(data))
+	}
+	keys := make([]K, 0, capacity)
+	values := make([]V, 0, capacity)
+	for i := uint64(0); i < count; i++ {
+		k, n, err := kc.Decode(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		data = data[n:]
+		v, n, err := vc.Decode(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		data = data[n:]
+		if len(keys) > 0 && keys[len(keys)-1] >= k {
+			return nil, nil, ErrUnsorted
+		}
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+	if len(data) != 0 {
+		return nil, nil, ErrInvalidData
+	}
+	return keys, values, nil
+}
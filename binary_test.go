@@ -0,0 +1,163 @@
+package rbtree
+
+import (
+	"encoding"
+	"testing"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*RBTree[int, int])(nil)
+	_ encoding.BinaryUnmarshaler = (*RBTree[int, int])(nil)
+)
+
+func TestRBTreeBuild(t *testing.T) {
+	for n := 0; n < 200; n++ {
+		keys := make([]int, n)
+		values := make([]int, n)
+		for i := range keys {
+			keys[i] = i * 2
+			values[i] = i
+		}
+		rbt := NewRBTree[int, int]()
+		rbt.build(keys, values)
+		if checkRBTree(t, rbt) != n {
+			t.Fatalf("error: rbtree size should %v", n)
+		}
+		// 重建后的树仍然可以正常插入删除
+		rbt.Put(1, 1)
+		rbt.Remove(0)
+		checkRBTree(t, rbt)
+	}
+}
+
+func TestRBTreeMarshalBinary(t *testing.T) {
+	rbt := NewRBTree[string, float64]()
+	for i := 0; i < 1000; i++ {
+		rbt.Put(string(rune('a'+i%26))+string(rune('a'+i/26)), float64(i)/3)
+	}
+	data, err := rbt.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := NewRBTree[string, float64]()
+	other.Put("zzz", 1)
+	if err := other.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	checkRBTree(t, other)
+	if other.size != rbt.size {
+		t.Fatalf("error: rbtree size should %v, but get %v", rbt.size, other.size)
+	}
+	rbt.walk(func(n *node[string, float64]) bool {
+		if v, ok := other.Get(n.key); !ok || v != n.value {
+			t.Fatalf("error: get %v should %v, but get %v", n.key, n.value, v)
+		}
+		return true
+	})
+}
+
+type point struct {
+	X, Y int
+}
+
+type level int8
+
+func TestRBTreeMarshalBinaryGob(t *testing.T) {
+	rbt := NewRBTree[level, point]()
+	rbt.Put(-3, point{1, 2})
+	rbt.Put(100, point{3, 4})
+	data, err := rbt.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := NewRBTree[level, point]()
+	if err := other.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := other.Get(-3); v != (point{1, 2}) {
+		t.Fatalf("error: get -3 should {1 2}, but get %v", v)
+	}
+	if v, _ := other.Get(100); v != (point{3, 4}) {
+		t.Fatalf("error: get 100 should {3 4}, but get %v", v)
+	}
+}
+
+func TestRBTreeUnmarshalBinaryInvalid(t *testing.T) {
+	rbt := NewRBTree[int, int]()
+	for i := 0; i < 10; i++ {
+		rbt.Put(i, i)
+	}
+	data, _ := rbt.MarshalBinary()
+
+	other := NewRBTree[int, int]()
+	for i := 0; i < len(data); i++ {
+		if other.UnmarshalBinary(data[:i]) == nil {
+			t.Fatalf("error: truncated data %v should fail", i)
+		}
+	}
+	if err := other.UnmarshalBinary(append(data, 0)); err != ErrInvalidData {
+		t.Fatalf("error: trailing data should return ErrInvalidData, but get %v", err)
+	}
+	unsorted := []byte{'R', 'B', 'T', binaryVersion, 2, 4, 0, 2, 0}
+	if err := other.UnmarshalBinary(unsorted); err != ErrUnsorted {
+		t.Fatalf("error: unsorted data should return ErrUnsorted, but get %v", err)
+	}
+	if other.size != 0 {
+		t.Fatal("error: failed unmarshal should keep rbtree unchanged")
+	}
+}
+
+// 把 uint8 编码为 1 个字节
+type byteCodec struct{}
+
+func (byteCodec) Append(buf []byte, v uint8) ([]byte, error) {
+	return append(buf, v), nil
+}
+
+func (byteCodec) Decode(data []byte) (uint8, int, error) {
+	if len(data) == 0 {
+		return 0, 0, errShortBuffer
+	}
+	return data[0], 1, nil
+}
+
+// value 不占空间
+type emptyCodec struct{}
+
+func (emptyCodec) Append(buf []byte, v struct{}) ([]byte, error) {
+	return buf, nil
+}
+
+func (emptyCodec) Decode(data []byte) (struct{}, int, error) {
+	return struct{}{}, 0, nil
+}
+
+// 每条记录只占 1 个字节
+func TestRBTreeMarshalBinarySmallRecords(t *testing.T) {
+	rbt := NewRBTree[uint8, struct{}]()
+	rbt.SetCodec(byteCodec{}, emptyCodec{})
+	for i := 0; i < 200; i++ {
+		rbt.Put(uint8(i), struct{}{})
+	}
+	data, err := rbt.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := NewRBTree[uint8, struct{}]()
+	other.SetCodec(byteCodec{}, emptyCodec{})
+	if err := other.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if checkRBTree(t, other) != 200 {
+		t.Fatalf("error: unmarshal size should 200, but get %v", other.Len())
+	}
+	if err := other.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Fatal("error: truncated data should fail")
+	}
+
+	// 异常的 count 不会导致分配过大的内存
+	huge := append([]byte{'R', 'B', 'T', binaryVersion}, appendUvarint(nil, 1<<62)...)
+	if err := other.UnmarshalBinary(append(huge, 1, 2)); err == nil {
+		t.Fatal("error: huge count should fail")
+	}
+}
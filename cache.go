@@ -0,0 +1,277 @@
+package rbtree
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/exp/constraints"
+)
+
+// 超出容量时淘汰哪个 key
+type EvictPolicy byte
+
+const (
+	// 最久未访问的 key（LRU）
+	EvictOldestAccess EvictPolicy = iota
+	// 最小的 key
+	EvictSmallestKey
+)
+
+// key 被淘汰的原因
+type EvictReason byte
+
+const (
+	EvictExpired EvictReason = iota
+	EvictCapacity
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictExpired:
+		return "expired"
+	case EvictCapacity:
+		return "capacity"
+	}
+	return "unknown"
+}
+
+type CacheOptions[K constraints.Ordered, V any] struct {
+	// 最多保存的 key 数量，0 表示不限制
+	Capacity int
+	Policy   EvictPolicy
+	// Put 使用的默认过期时间，0 表示不过期
+	TTL time.Duration
+	// 大于 0 时启动后台 goroutine 定期调用 Sweep，需要调用 Close 停止
+	SweepInterval time.Duration
+	// key 因过期或超出容量被淘汰时调用，调用时不持有锁；Remove 删除的 key 不会触发
+	OnEvict func(key K, value V, reason EvictReason)
+}
+
+type cacheEntry[V any] struct {
+	value V
+	// 过期时间（UnixNano），0 表示不过期
+	deadline int64
+	// 最近一次访问的序号
+	tick uint64
+}
+
+type evicted[K any, V any] struct {
+	key    K
+	value  V
+	reason EvictReason
+}
+
+// Cache 是带容量限制和过期时间的有序缓存
+// 数据存放在按 key 排序的红黑树中，另外用两棵红黑树作为索引：
+// 按过期时间排序的 MultiTree 使 Sweep 只需要访问已过期的 key，
+// 按访问序号排序的红黑树用于找到最久未访问的 key
+// 过期的 key 在 Get 时惰性删除，或者由 Sweep 批量删除
+type Cache[K constraints.Ordered, V any] struct {
+	mu        sync.Mutex
+	opts      CacheOptions[K, V]
+	tree      *RBTree[K, *cacheEntry[V]]
+	deadlines *MultiTree[int64, K]
+	access    *RBTree[uint64, K]
+	tick      uint64
+	now       func() time.Time
+	stop      chan struct{}
+}
+
+func NewCache[K constraints.Ordered, V any](opts *CacheOptions[K, V]) *Cache[K, V] {
+	c := &Cache[K, V]{
+		tree:      NewRBTree[K, *cacheEntry[V]](),
+		deadlines: NewMultiTree[int64, K](),
+		access:    NewRBTree[uint64, K](),
+		now:       time.Now,
+	}
+	if opts != nil {
+		c.opts = *opts
+	}
+	if c.opts.SweepInterval > 0 {
+		c.stop = make(chan struct{})
+		go c.sweepLoop(c.opts.SweepInterval, c.stop)
+	}
+	return c
+}
+
+func (c *Cache[K, V]) sweepLoop(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.Sweep()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Close 停止后台清理，缓存仍然可以使用
+func (c *Cache[K, V]) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+}
+
+// Put 使用默认过期时间写入
+func (c *Cache[K, V]) Put(key K, value V) {
+	c.PutWithTTL(key, value, c.opts.TTL)
+}
+
+// PutWithTTL 写入 key，ttl 为 0 表示不过期
+func (c *Cache[K, V]) PutWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	var deadline int64
+	if ttl > 0 {
+		deadline = c.now().Add(ttl).UnixNano()
+	}
+	if e, ok := c.tree.Get(key); ok {
+		c.unindex(key, e)
+	}
+	e := &cacheEntry[V]{value: value, deadline: deadline}
+	c.tree.Put(key, e)
+	c.index(key, e)
+
+	var evicts []evicted[K, V]
+	for c.opts.Capacity > 0 && c.tree.Len() > c.opts.Capacity {
+		var victim K
+		if c.opts.Policy == EvictSmallestKey {
+			victim, _, _ = c.tree.Min()
+		} else {
+			_, victim, _ = c.access.Min()
+		}
+		evicts = append(evicts, c.remove(victim, EvictCapacity))
+	}
+	c.mu.Unlock()
+	c.notify(evicts)
+}
+
+// Get 返回未过期的 value，并更新访问顺序；已过期的 key 会被删除
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	c.mu.Lock()
+	e, ok := c.tree.Get(key)
+	if !ok {
+		c.mu.Unlock()
+		return value, false
+	}
+	if c.expired(e, c.now().UnixNano()) {
+		ev := c.remove(key, EvictExpired)
+		c.mu.Unlock()
+		c.notify([]evicted[K, V]{ev})
+		return value, false
+	}
+	c.access.Remove(e.tick)
+	c.tick++
+	e.tick = c.tick
+	c.access.Put(e.tick, key)
+	c.mu.Unlock()
+	return e.value, true
+}
+
+func (c *Cache[K, V]) Remove(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.tree.Get(key)
+	if !ok {
+		return false
+	}
+	c.unindex(key, e)
+	c.tree.Remove(key)
+	return true
+}
+
+// Len 返回缓存中 key 的数量，包括已过期但还没有被删除的
+func (c *Cache[K, V]) Len() int {
+	return c.tree.Len()
+}
+
+// Ascend 按 key 升序遍历未过期的 key，不更新访问顺序，fn 返回 false 时停止
+// 先在锁内复制所有未过期的条目，再在锁外调用 fn，fn 中可以访问缓存，
+// 但遍历的是调用 Ascend 时的内容
+func (c *Cache[K, V]) Ascend(fn func(key K, value V) bool) {
+	c.mu.Lock()
+	now := c.now().UnixNano()
+	var (
+		keys   []K
+		values []V
+	)
+	c.tree.Ascend(func(key K, e *cacheEntry[V]) bool {
+		if !c.expired(e, now) {
+			keys = append(keys, key)
+			values = append(values, e.value)
+		}
+		return true
+	})
+	c.mu.Unlock()
+	for i, key := range keys {
+		if !fn(key, values[i]) {
+			return
+		}
+	}
+}
+
+// Sweep 删除所有已过期的 key，返回删除的数量
+// 只访问过期时间索引中已到期的部分，不会扫描整个缓存
+func (c *Cache[K, V]) Sweep() int {
+	c.mu.Lock()
+	now := c.now().UnixNano()
+	var evicts []evicted[K, V]
+	for {
+		deadline, keys, ok := c.deadlines.tree.Min()
+		if !ok || deadline > now {
+			break
+		}
+		// remove 会修改 keys 所在的数组，先复制一份
+		for _, key := range append([]K(nil), keys...) {
+			evicts = append(evicts, c.remove(key, EvictExpired))
+		}
+	}
+	c.mu.Unlock()
+	c.notify(evicts)
+	return len(evicts)
+}
+
+func (c *Cache[K, V]) expired(e *cacheEntry[V], now int64) bool {
+	return e.deadline != 0 && e.deadline <= now
+}
+
+// 调用方需持有 c.mu
+func (c *Cache[K, V]) index(key K, e *cacheEntry[V]) {
+	c.tick++
+	e.tick = c.tick
+	c.access.Put(e.tick, key)
+	if e.deadline != 0 {
+		c.deadlines.Put(e.deadline, key)
+	}
+}
+
+// 调用方需持有 c.mu
+func (c *Cache[K, V]) unindex(key K, e *cacheEntry[V]) {
+	c.access.Remove(e.tick)
+	if e.deadline != 0 {
+		c.deadlines.RemoveOne(e.deadline, func(k K) bool {
+			return k == key
+		})
+	}
+}
+
+// 调用方需持有 c.mu，key 必须存在
+func (c *Cache[K, V]) remove(key K, reason EvictReason) evicted[K, V] {
+	e, _ := c.tree.Get(key)
+	c.unindex(key, e)
+	c.tree.Remove(key)
+	return evicted[K, V]{key, e.value, reason}
+}
+
+func (c *Cache[K, V]) notify(evicts []evicted[K, V]) {
+	if c.opts.OnEvict == nil {
+		return
+	}
+	for _, ev := range evicts {
+		c.opts.OnEvict(ev.key, ev.value, ev.reason)
+	}
+}
@@ -0,0 +1,164 @@
+package rbtree
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/constraints"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func newTestCache[K constraints.Ordered, V any](opts *CacheOptions[K, V]) (*Cache[K, V], *fakeClock) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	c := NewCache(opts)
+	c.now = clock.now
+	return c, clock
+}
+
+func TestCacheTTL(t *testing.T) {
+	var evicts []string
+	c, clock := newTestCache(&CacheOptions[int, string]{
+		TTL: time.Minute,
+		OnEvict: func(k int, v string, reason EvictReason) {
+			evicts = append(evicts, fmt.Sprintf("%v %v %v", k, v, reason))
+		},
+	})
+	c.Put(1, "a")
+	c.PutWithTTL(2, "b", 2*time.Minute)
+	c.PutWithTTL(3, "c", 0)
+	c.Put(4, "d")
+
+	clock.t = clock.t.Add(90 * time.Second)
+	if _, ok := c.Get(1); ok {
+		t.Fatal("error: 1 should expired")
+	}
+	if v, ok := c.Get(2); !ok || v != "b" {
+		t.Fatalf("error: get 2 should b, but get %v", v)
+	}
+	var keys []int
+	c.Ascend(func(k int, _ string) bool {
+		keys = append(keys, k)
+		return true
+	})
+	if fmt.Sprint(keys) != "[2 3]" {
+		t.Fatalf("error: ascend should [2 3], but get %v", keys)
+	}
+	if c.Sweep() != 1 || c.Len() != 2 {
+		t.Fatal("error: sweep should remove 4")
+	}
+
+	clock.t = clock.t.Add(time.Hour)
+	if c.Sweep() != 1 || c.Len() != 1 {
+		t.Fatal("error: sweep should remove 2")
+	}
+	if fmt.Sprint(evicts) != "[1 a expired 4 d expired 2 b expired]" {
+		t.Fatalf("error: evicts get %v", evicts)
+	}
+	if c.deadlines.Len() != 0 || c.access.Len() != 1 {
+		t.Fatal("error: indexes should only contain 3")
+	}
+}
+
+func TestCacheRefreshTTL(t *testing.T) {
+	c, clock := newTestCache(&CacheOptions[string, int]{TTL: time.Minute})
+	c.Put("a", 1)
+	clock.t = clock.t.Add(50 * time.Second)
+	c.Put("a", 2)
+	clock.t = clock.t.Add(50 * time.Second)
+	if c.Sweep() != 0 {
+		t.Fatal("error: rewritten key should not expired")
+	}
+	if v, ok := c.Get("a"); !ok || v != 2 {
+		t.Fatalf("error: get a should 2, but get %v", v)
+	}
+	if c.deadlines.Len() != 1 {
+		t.Fatalf("error: deadline index should contain 1 key, but get %v", c.deadlines.Len())
+	}
+}
+
+func TestCacheCapacityLRU(t *testing.T) {
+	var evicts []int
+	c, _ := newTestCache(&CacheOptions[int, int]{
+		Capacity: 3,
+		OnEvict: func(k, _ int, reason EvictReason) {
+			if reason != EvictCapacity {
+				t.Fatalf("error: reason should EvictCapacity, but get %v", reason)
+			}
+			evicts = append(evicts, k)
+		},
+	})
+	c.Put(1, 1)
+	c.Put(2, 2)
+	c.Put(3, 3)
+	c.Get(1)
+	c.Put(4, 4)
+	c.Put(5, 5)
+	if fmt.Sprint(evicts) != "[2 3]" {
+		t.Fatalf("error: evicts should [2 3], but get %v", evicts)
+	}
+	if _, ok := c.Get(1); !ok {
+		t.Fatal("error: recently accessed 1 should kept")
+	}
+	if !c.Remove(4) || c.Remove(4) || c.Len() != 2 {
+		t.Fatal("error: remove 4 should only succeed once")
+	}
+}
+
+func TestCacheCapacitySmallestKey(t *testing.T) {
+	c, _ := newTestCache(&CacheOptions[int, int]{Capacity: 2, Policy: EvictSmallestKey})
+	c.Put(5, 5)
+	c.Put(3, 3)
+	c.Get(3)
+	c.Put(7, 7)
+	if _, ok := c.Get(3); ok {
+		t.Fatal("error: smallest key 3 should evicted")
+	}
+	if c.Len() != 2 {
+		t.Fatalf("error: len should 2, but get %v", c.Len())
+	}
+}
+
+func TestCacheSweepLoop(t *testing.T) {
+	c := NewCache(&CacheOptions[int, int]{TTL: time.Millisecond, SweepInterval: time.Millisecond})
+	defer c.Close()
+	c.Put(1, 1)
+	for i := 0; i < 1000 && c.Len() != 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if c.Len() != 0 {
+		t.Fatal("error: background sweep should remove expired key")
+	}
+}
+
+// fn 中访问缓存不会死锁
+func TestCacheAscendReentrant(t *testing.T) {
+	c, _ := newTestCache(&CacheOptions[int, int]{})
+	for i := 0; i < 10; i++ {
+		c.Put(i, i)
+	}
+	var keys []int
+	c.Ascend(func(k, v int) bool {
+		if _, ok := c.Get(0); !ok {
+			t.Fatal("error: get 0 should exist")
+		}
+		c.Remove(k + 1)
+		c.Put(k+100, v)
+		keys = append(keys, k)
+		return k < 5
+	})
+	// 遍历的是调用 Ascend 时的内容
+	if fmt.Sprint(keys) != "[0 1 2 3 4 5]" {
+		t.Fatalf("error: ascend should [0 1 2 3 4 5], but get %v", keys)
+	}
+	if _, ok := c.Get(3); ok || c.Len() != 10 {
+		t.Fatalf("error: writes in fn should apply, but len %v", c.Len())
+	}
+}
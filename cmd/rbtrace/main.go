@@ -0,0 +1,227 @@
+// rbtrace 依次执行一组插入和删除操作，输出每一步之后的红黑树以及调整的过程
+//
+// 操作从文件或标准输入读取，以空白分隔：
+//
+//	put 10 put 20 put 30
+//	del 20
+//	15 25
+//
+// 单独的整数等同于 put。默认输出 ASCII 图，-html 输出可以离线打开的动画页面：
+//
+//	echo 10 20 30 40 50 del 20 | rbtrace -html > trace.html
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"rbtree"
+)
+
+type op struct {
+	del bool
+	key int
+}
+
+func (o op) String() string {
+	if o.del {
+		return fmt.Sprintf("del %d", o.key)
+	}
+	return fmt.Sprintf("put %d", o.key)
+}
+
+func parseOps(r io.Reader) ([]op, error) {
+	sc := bufio.NewScanner(r)
+	sc.Split(bufio.ScanWords)
+	var ops []op
+	for sc.Scan() {
+		word := sc.Text()
+		o := op{}
+		switch word {
+		case "put", "del":
+			o.del = word == "del"
+			if !sc.Scan() {
+				return nil, fmt.Errorf("missing key after %q", word)
+			}
+			word = sc.Text()
+		}
+		k, err := strconv.Atoi(word)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q", word)
+		}
+		o.key = k
+		ops = append(ops, o)
+	}
+	return ops, sc.Err()
+}
+
+type frame struct {
+	Title  string
+	Events []string
+	Tree   template.HTML
+}
+
+// 执行所有操作，每一步之后调用 fn
+func replay(ops []op, opts *rbtree.RenderOptions[int, struct{}], fn func(o op, events []string, tree string)) {
+	rbt := rbtree.NewRBTree[int, struct{}]()
+	var events []string
+	rbt.SetTracer(rbtree.TraceFunc[int](func(e rbtree.TraceEvent[int]) {
+		events = append(events, e.String())
+	}))
+	for _, o := range ops {
+		events = events[:0]
+		if o.del {
+			if !rbt.Remove(o.key) {
+				events = append(events, "not found")
+			}
+		} else {
+			rbt.Put(o.key, struct{}{})
+		}
+		var sb strings.Builder
+		rbt.Render(&sb, opts)
+		fn(o, events, sb.String())
+	}
+}
+
+func writeText(w io.Writer, ops []op, opts *rbtree.RenderOptions[int, struct{}]) error {
+	bw := bufio.NewWriter(w)
+	i := 0
+	replay(ops, opts, func(o op, events []string, tree string) {
+		i++
+		fmt.Fprintf(bw, "#%d %v\n", i, o)
+		for _, e := range events {
+			fmt.Fprintf(bw, "  %s\n", e)
+		}
+		fmt.Fprintf(bw, "%s\n", tree)
+	})
+	return bw.Flush()
+}
+
+// Render 输出的 ANSI 颜色转换为 HTML
+var ansiToHTML = strings.NewReplacer("\x1b[31m", `<span class="r">`, "\x1b[0m", "</span>")
+
+func writeHTML(w io.Writer, ops []op, leaves bool) error {
+	frames := []frame{{Title: "empty", Tree: "empty"}}
+	opts := &rbtree.RenderOptions[int, struct{}]{Color: true, Leaves: leaves}
+	i := 0
+	replay(ops, opts, func(o op, events []string, tree string) {
+		i++
+		frames = append(frames, frame{
+			Title:  fmt.Sprintf("#%d %v", i, o),
+			Events: append([]string(nil), events...),
+			Tree:   template.HTML(ansiToHTML.Replace(html.EscapeString(tree))),
+		})
+	})
+	return page.Execute(w, frames)
+}
+
+var page = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>rbtrace</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+pre { font-size: 16px; line-height: 1.3; }
+.r { color: #d00; font-weight: bold; }
+.frame { display: none; }
+.frame.current { display: block; }
+.events { color: #555; font-family: monospace; }
+</style>
+</head>
+<body>
+<div>
+<button id="prev">&larr;</button>
+<button id="play">play</button>
+<button id="next">&rarr;</button>
+<input id="pos" type="range" min="0" max="{{len .}}" value="0">
+</div>
+{{range .}}<div class="frame">
+<h3>{{.Title}}</h3>
+<ul class="events">{{range .Events}}<li>{{.}}</li>{{end}}</ul>
+<pre>{{.Tree}}</pre>
+</div>
+{{end}}<script>
+var frames = document.querySelectorAll(".frame");
+var pos = document.getElementById("pos");
+var cur = 0, timer = null;
+pos.max = frames.length - 1;
+function show(i) {
+	if (i < 0 || i >= frames.length) return false;
+	frames[cur].classList.remove("current");
+	cur = i;
+	frames[cur].classList.add("current");
+	pos.value = cur;
+	return true;
+}
+function stop() {
+	clearInterval(timer);
+	timer = null;
+	document.getElementById("play").textContent = "play";
+}
+document.getElementById("prev").onclick = function() { stop(); show(cur - 1); };
+document.getElementById("next").onclick = function() { stop(); show(cur + 1); };
+pos.oninput = function() { stop(); show(+pos.value); };
+document.getElementById("play").onclick = function() {
+	if (timer) { stop(); return; }
+	if (cur == frames.length - 1) show(0);
+	this.textContent = "pause";
+	timer = setInterval(function() { if (!show(cur + 1)) stop(); }, 1000);
+};
+document.onkeydown = function(e) {
+	if (e.key == "ArrowLeft") { stop(); show(cur - 1); }
+	if (e.key == "ArrowRight") { stop(); show(cur + 1); }
+};
+show(0);
+</script>
+</body>
+</html>
+`))
+
+func main() {
+	htmlOut := flag.Bool("html", false, "output an animated HTML page instead of ASCII")
+	color := flag.Bool("color", false, "use ANSI colors for red nodes")
+	leaves := flag.Bool("leaves", false, "show leaf nodes")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: rbtrace [flags] [file ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var r io.Reader = os.Stdin
+	if flag.NArg() > 0 {
+		var readers []io.Reader
+		for _, name := range flag.Args() {
+			f, err := os.Open(name)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			defer f.Close()
+			readers = append(readers, f)
+		}
+		r = io.MultiReader(readers...)
+	}
+	ops, err := parseOps(r)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "rbtrace:", err)
+		os.Exit(1)
+	}
+
+	if *htmlOut {
+		err = writeHTML(os.Stdout, ops, *leaves)
+	} else {
+		err = writeText(os.Stdout, ops, &rbtree.RenderOptions[int, struct{}]{Color: *color, Leaves: *leaves})
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "rbtrace:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"rbtree"
+)
+
+func TestParseOps(t *testing.T) {
+	ops, err := parseOps(strings.NewReader("put 1 2\ndel 1  -3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []op{{key: 1}, {key: 2}, {del: true, key: 1}, {key: -3}}
+	if len(ops) != len(want) {
+		t.Fatalf("error: ops should %v, but get %v", want, ops)
+	}
+	for i := range want {
+		if ops[i] != want[i] {
+			t.Fatalf("error: op %v should %v, but get %v", i, want[i], ops[i])
+		}
+	}
+	for _, in := range []string{"put", "del x", "abc"} {
+		if _, err := parseOps(strings.NewReader(in)); err == nil {
+			t.Fatalf("error: parse %q should fail", in)
+		}
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	ops, _ := parseOps(strings.NewReader("10 20 30 del 7"))
+	var sb strings.Builder
+	if err := writeText(&sb, ops, &rbtree.RenderOptions[int, struct{}]{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"#3 put 30\n  insert case 3.2.1 at 30\n  rotate left at 10\n",
+		"#4 del 7\n  not found\n",
+	} {
+		if !strings.Contains(sb.String(), s) {
+			t.Fatalf("error: output should contain %q, but get\n%v", s, sb.String())
+		}
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	ops, _ := parseOps(strings.NewReader("10 20"))
+	var sb strings.Builder
+	if err := writeHTML(&sb, ops, false); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(sb.String(), `<div class="frame">`) != 3 ||
+		!strings.Contains(sb.String(), `<span class="r">20</span>`) {
+		t.Fatalf("error: html get\n%v", sb.String())
+	}
+}
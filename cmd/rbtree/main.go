@@ -0,0 +1,197 @@
+// rbtree 是操作 RBTree[string, string] 的交互式命令行，可以逐条输入命令，
+// 也可以从文件重放一组命令来复现问题：
+//
+//	rbtree < ops.txt
+//	rbtree ops.txt
+//
+// 输入 help 查看支持的命令
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"rbtree"
+)
+
+const usage = `commands:
+  put k v       insert or update k, v is the rest of the line
+  get k         print the value of k
+  del k         remove k
+  range lo hi   print entries in [lo, hi)
+  floor k       print the largest entry <= k
+  print         draw the tree
+  validate      check red-black invariants
+  stats         print structural statistics
+  save file     write the tree in binary format
+  load file     replace the tree with the content of file
+  replay file   apply the operations recorded by rbtree.Recorder and validate after each
+  help          show this message
+  quit          exit
+`
+
+var errQuit = errors.New("quit")
+
+type repl struct {
+	tree *rbtree.RBTree[string, string]
+	out  io.Writer
+}
+
+func newREPL(out io.Writer) *repl {
+	return &repl{tree: rbtree.NewRBTree[string, string](), out: out}
+}
+
+// 命令的参数个数，put 的 value 可以包含空格，单独处理
+var arity = map[string]int{
+	"get": 1, "del": 1, "range": 2, "floor": 1, "print": 0,
+	"validate": 0, "stats": 0, "save": 1, "load": 1, "replay": 1, "help": 0, "quit": 0, "exit": 0,
+}
+
+// exec 执行一行命令，空行和 # 开头的注释被忽略
+func (r *repl) exec(line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+	fields := strings.Fields(line)
+	cmd, args := fields[0], fields[1:]
+	if cmd == "put" {
+		if len(args) < 2 {
+			return fmt.Errorf("usage: put k v")
+		}
+		// value 保留 key 之后的原始内容
+		rest := strings.TrimSpace(line[len("put"):])
+		value := strings.TrimSpace(rest[len(args[0]):])
+		r.tree.Put(args[0], value)
+		return nil
+	}
+	n, ok := arity[cmd]
+	if !ok {
+		return fmt.Errorf("unknown command %q, try help", cmd)
+	}
+	if len(args) != n {
+		return fmt.Errorf("%s expects %d argument(s)", cmd, n)
+	}
+
+	switch cmd {
+	case "get":
+		v, ok := r.tree.Get(args[0])
+		if !ok {
+			return fmt.Errorf("%q not found", args[0])
+		}
+		fmt.Fprintln(r.out, v)
+	case "del":
+		if !r.tree.Remove(args[0]) {
+			return fmt.Errorf("%q not found", args[0])
+		}
+	case "range":
+		r.tree.AscendRange(args[0], args[1], func(k, v string) bool {
+			fmt.Fprintf(r.out, "%s %s\n", k, v)
+			return true
+		})
+	case "floor":
+		k, v, ok := r.tree.Floor(args[0])
+		if !ok {
+			return fmt.Errorf("no key <= %q", args[0])
+		}
+		fmt.Fprintf(r.out, "%s %s\n", k, v)
+	case "print":
+		return r.tree.Render(r.out, nil)
+	case "validate":
+		if err := r.tree.Validate(); err != nil {
+			return err
+		}
+		fmt.Fprintln(r.out, "ok")
+	case "stats":
+		s := r.tree.Stats()
+		fmt.Fprintf(r.out, "size %d height %d black-height %d depth %d..%d avg %.2f red %d black %d memory %dB\n",
+			s.Size, s.Height, s.BlackHeight, s.MinDepth, s.MaxDepth, s.AvgDepth, s.RedNodes, s.BlackNodes, s.MemoryBytes)
+	case "save":
+		data, err := r.tree.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(args[0], data, 0o644)
+	case "load":
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		// 读取失败时保留原来的树
+		tree := rbtree.NewRBTree[string, string]()
+		if err := tree.UnmarshalBinary(data); err != nil {
+			return err
+		}
+		r.tree = tree
+	case "replay":
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		ops, err := rbtree.ReadOpLog[string, string](f, nil, nil)
+		if err != nil {
+			return err
+		}
+		if err := rbtree.Replay(r.tree, ops, true); err != nil {
+			return err
+		}
+		fmt.Fprintf(r.out, "%d ops\n", len(ops))
+	case "help":
+		fmt.Fprint(r.out, usage)
+	case "quit", "exit":
+		return errQuit
+	}
+	return nil
+}
+
+// run 逐行执行命令直到输入结束或 quit，返回出错的命令数
+func (r *repl) run(in io.Reader, prompt string, errOut io.Writer) (int, error) {
+	sc := bufio.NewScanner(in)
+	failed := 0
+	for lineno := 1; ; lineno++ {
+		fmt.Fprint(r.out, prompt)
+		if !sc.Scan() {
+			break
+		}
+		err := r.exec(sc.Text())
+		if err == errQuit {
+			return failed, nil
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintf(errOut, "line %d: %v\n", lineno, err)
+		}
+	}
+	return failed, sc.Err()
+}
+
+func main() {
+	var in io.Reader = os.Stdin
+	prompt := ""
+	if len(os.Args) > 1 {
+		f, err := os.Open(os.Args[1])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		defer f.Close()
+		in = f
+	} else if fi, err := os.Stdin.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		// 只在终端中交互使用时显示提示符
+		prompt = "> "
+	}
+
+	failed, err := newREPL(os.Stdout).run(in, prompt, os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if failed > 0 && prompt == "" {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rbtree"
+)
+
+func TestREPL(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "tree.rbt")
+	script := `# comment
+put b 2
+put a 1
+put c hello  world
+get c
+floor bb
+range a c
+del b
+del b
+validate
+save ` + file + `
+put d 4
+load ` + file + `
+get d
+unknown
+stats
+quit
+get a
+`
+	var out, errOut strings.Builder
+	failed, err := newREPL(&out).run(strings.NewReader(script), "", &errOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantOut := "hello  world\nb 2\na 1\nb 2\nok\nsize 2 "
+	if !strings.HasPrefix(out.String(), wantOut) {
+		t.Fatalf("error: output should start with %q, but get %q", wantOut, out.String())
+	}
+	wantErr := "line 9: \"b\" not found\nline 14: \"d\" not found\nline 15: unknown command \"unknown\", try help\n"
+	if failed != 3 || errOut.String() != wantErr {
+		t.Fatalf("error: errors should %q, but get %v %q", wantErr, failed, errOut.String())
+	}
+}
+
+func TestREPLPrint(t *testing.T) {
+	r := newREPL(new(strings.Builder))
+	for _, line := range []string{"put b 2", "put a 1", "put c 3", "print"} {
+		if err := r.exec(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "     b(b)\n    /     \\\na(r)      c(r)\n"
+	if got := r.out.(*strings.Builder).String(); got != want {
+		t.Fatalf("error: print should\n%q, but get\n%q", want, got)
+	}
+	for _, line := range []string{"put a", "get", "range a", "load /nonexistent"} {
+		if r.exec(line) == nil {
+			t.Fatalf("error: %q should fail", line)
+		}
+	}
+}
+
+func TestREPLReplay(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ops.log")
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, _ := rbtree.NewRecorder(rbtree.NewRBTree[string, string](), f)
+	rec.Put("a", "1")
+	rec.Put("b", "2")
+	rec.Remove("a")
+	if err := rec.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	var out strings.Builder
+	r := newREPL(&out)
+	for _, line := range []string{"replay " + file, "range a z"} {
+		if err := r.exec(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if out.String() != "3 ops\nb 2\n" {
+		t.Fatalf("error: replay output get %q", out.String())
+	}
+}
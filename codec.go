@@ -0,0 +1,142 @@
+package rbtree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"math"
+	"reflect"
+)
+
+var errShortBuffer = errors.New("rbtree: unexpected end of data")
+
+// Codec 负责单个 key 或 value 的二进制编解码
+// Append 将 v 编码后追加到 buf，Decode 从 data 开头解码出一个值并返回消耗的字节数
+type Codec[T any] interface {
+	Append(buf []byte, v T) ([]byte, error)
+	Decode(data []byte) (T, int, error)
+}
+
+// DefaultCodec 返回 T 的默认编解码器
+// 整数、浮点数、字符串、布尔（包括以它们为底层类型的自定义类型）使用紧凑的变长编码，
+// 其他类型使用 encoding/gob
+func DefaultCodec[T any]() Codec[T] {
+	var v T
+	switch reflect.TypeOf(&v).Elem().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String, reflect.Bool:
+		return basicCodec[T]{}
+	}
+	return gobCodec[T]{}
+}
+
+type basicCodec[T any] struct{}
+
+func (basicCodec[T]) Append(buf []byte, v T) ([]byte, error) {
+	rv := reflect.ValueOf(&v).Elem()
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendVarint(buf, rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendUvarint(buf, rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(rv.Float()))
+		return append(buf, b[:]...), nil
+	case reflect.String:
+		s := rv.String()
+		buf = appendUvarint(buf, uint64(len(s)))
+		return append(buf, s...), nil
+	case reflect.Bool:
+		if rv.Bool() {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	}
+	return nil, errors.New("rbtree: unsupported type " + rv.Type().String())
+}
+
+func (basicCodec[T]) Decode(data []byte) (v T, n int, err error) {
+	rv := reflect.ValueOf(&v).Elem()
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var x int64
+		x, n = binary.Varint(data)
+		if n <= 0 || rv.OverflowInt(x) {
+			return v, 0, errShortBuffer
+		}
+		rv.SetInt(x)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var x uint64
+		x, n = binary.Uvarint(data)
+		if n <= 0 || rv.OverflowUint(x) {
+			return v, 0, errShortBuffer
+		}
+		rv.SetUint(x)
+	case reflect.Float32, reflect.Float64:
+		if len(data) < 8 {
+			return v, 0, errShortBuffer
+		}
+		rv.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(data)))
+		n = 8
+	case reflect.String:
+		l, m := binary.Uvarint(data)
+		if m <= 0 || uint64(len(data)-m) < l {
+			return v, 0, errShortBuffer
+		}
+		rv.SetString(string(data[m : m+int(l)]))
+		n = m + int(l)
+	case reflect.Bool:
+		if len(data) < 1 || data[0] > 1 {
+			return v, 0, errShortBuffer
+		}
+		rv.SetBool(data[0] == 1)
+		n = 1
+	default:
+		return v, 0, errors.New("rbtree: unsupported type " + rv.Type().String())
+	}
+	return v, n, nil
+}
+
+// gob 编码的结果带长度前缀
+type gobCodec[T any] struct{}
+
+func (gobCodec[T]) Append(buf []byte, v T) ([]byte, error) {
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(&v); err != nil {
+		return nil, err
+	}
+	buf = appendUvarint(buf, uint64(b.Len()))
+	return append(buf, b.Bytes()...), nil
+}
+
+func (gobCodec[T]) Decode(data []byte) (v T, n int, err error) {
+	l, m := binary.Uvarint(data)
+	if m <= 0 || uint64(len(data)-m) < l {
+		return v, 0, errShortBuffer
+	}
+	if err = gob.NewDecoder(bytes.NewReader(data[m : m+int(l)])).Decode(&v); err != nil {
+		return v, 0, err
+	}
+	return v, m + int(l), nil
+}
+
+func appendVarint(buf []byte, x int64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(b[:], x)
+	return append(buf, b[:n]...)
+}
+
+func appendUvarint(buf []byte, x uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], x)
+	return append(buf, b[:n]...)
+}
+
+func appendUint64(buf []byte, x uint64) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], x)
+	return append(buf, b[:]...)
+}
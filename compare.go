@@ -0,0 +1,181 @@
+package rbtree
+
+import (
+	"math/bits"
+	"unsafe"
+
+	"golang.org/x/exp/constraints"
+)
+
+// 按地址顺序对两棵树加读锁，避免两个 goroutine 以相反的顺序加锁时与等待中的写者死锁
+func rlockPair[K constraints.Ordered, V any](a, b *RBTree[K, V]) (unlock func()) {
+	if a == b {
+		a.mu.RLock()
+		return a.mu.RUnlock
+	}
+	if uintptr(unsafe.Pointer(a)) > uintptr(unsafe.Pointer(b)) {
+		a, b = b, a
+	}
+	a.mu.RLock()
+	b.mu.RLock()
+	return func() {
+		b.mu.RUnlock()
+		a.mu.RUnlock()
+	}
+}
+
+// Equal 判断两棵树是否包含相同的 (key, value)，与树的形状无关，O(n)
+func Equal[K constraints.Ordered, V any](a, b *RBTree[K, V], eq func(x, y V) bool) bool {
+	unlock := rlockPair(a, b)
+	defer unlock()
+	if a.size != b.size {
+		return false
+	}
+	for x, y := a.first(), b.first(); x != nil; x, y = a.successor(x), b.successor(y) {
+		if x.key != y.key || !eq(x.value, y.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare 按字典序比较两棵树按 key 升序排列的 (key, value) 序列：
+// 先比较 key，key 相同时用 cmp 比较 value，一个序列是另一个的前缀时较短的更小
+// 返回 -1、0 或 1
+func Compare[K constraints.Ordered, V any](a, b *RBTree[K, V], cmp func(x, y V) int) int {
+	unlock := rlockPair(a, b)
+	defer unlock()
+	x, y := a.first(), b.first()
+	for ; x != nil && y != nil; x, y = a.successor(x), b.successor(y) {
+		if x.key < y.key {
+			return -1
+		}
+		if x.key > y.key {
+			return 1
+		}
+		if c := cmp(x.value, y.value); c != 0 {
+			if c < 0 {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case x == nil && y == nil:
+		return 0
+	case x == nil:
+		return -1
+	}
+	return 1
+}
+
+// 多项式哈希在模 2^61-1 下计算
+const (
+	fingerprintMod  = 1<<61 - 1
+	fingerprintBase = 0x1fb1_c2d3_e4f5_0617 % fingerprintMod
+)
+
+func mulMod61(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	// hi*2^64 + lo ≡ hi*8 + (lo>>61) + (lo&M)  (mod 2^61-1)
+	r := hi<<3 + lo>>61 + lo&fingerprintMod
+	r = r>>61 + r&fingerprintMod
+	if r >= fingerprintMod {
+		r -= fingerprintMod
+	}
+	return r
+}
+
+// Fingerprint 是 (key, value) 序列的多项式哈希 Σ h(e_i)·B^(n-1-i)，
+// 只取决于内容而与树的形状无关，可以用来检测两个副本是否不一致
+// 作为 AugmentedTree 的聚合结果时可以增量维护，见 FingerprintAggregator
+type Fingerprint struct {
+	hash uint64
+	// B^n
+	pow uint64
+}
+
+// Sum 返回 64 位的指纹，内容相同的树指纹一定相同
+func (f Fingerprint) Sum() uint64 {
+	return f.hash
+}
+
+func (f Fingerprint) combine(g Fingerprint) Fingerprint {
+	h := mulMod61(f.hash, g.pow) + g.hash
+	if h >= fingerprintMod {
+		h -= fingerprintMod
+	}
+	return Fingerprint{hash: h, pow: mulMod61(f.pow, g.pow)}
+}
+
+func entryFingerprint(h uint64) Fingerprint {
+	return Fingerprint{hash: h % fingerprintMod, pow: fingerprintBase}
+}
+
+// 用编解码器编码后计算单个 (key, value) 的哈希
+func hashEntry[K any, V any](buf []byte, kc Codec[K], vc Codec[V], key K, value V) ([]byte, uint64, error) {
+	buf, err := kc.Append(buf[:0], key)
+	if err != nil {
+		return buf, 0, err
+	}
+	if buf, err = vc.Append(buf, value); err != nil {
+		return buf, 0, err
+	}
+	return buf, fnv64a(buf), nil
+}
+
+// FingerprintAggregator 在 AugmentedTree 中增量维护 Fingerprint：
+//
+//	at := NewAugmentedTree[K, V, Fingerprint](FingerprintAggregator[K, V]{})
+//	at.AggregateAll().Sum()
+//
+// Hash 为 nil 时用 DefaultCodec 编码 key、value 后计算哈希，与 RBTree.Fingerprint 的结果相同，
+// 编码失败时 panic；DefaultCodec 不支持的类型需要提供 Hash
+type FingerprintAggregator[K any, V any] struct {
+	Hash func(key K, value V) uint64
+}
+
+func (FingerprintAggregator[K, V]) Identity() Fingerprint {
+	return Fingerprint{pow: 1}
+}
+
+func (FingerprintAggregator[K, V]) Combine(a, b Fingerprint) Fingerprint {
+	return a.combine(b)
+}
+
+func (f FingerprintAggregator[K, V]) FromEntry(key K, value V) Fingerprint {
+	if f.Hash != nil {
+		return entryFingerprint(f.Hash(key, value))
+	}
+	_, h, err := hashEntry(nil, DefaultCodec[K](), DefaultCodec[V](), key, value)
+	if err != nil {
+		panic(err)
+	}
+	return entryFingerprint(h)
+}
+
+// Fingerprint 遍历整棵树计算内容的指纹，O(n)，key、value 用 SetCodec 设置的编解码器编码
+// 需要频繁比较时使用 FingerprintAggregator 增量维护
+func (rbt *RBTree[K, V]) Fingerprint() (uint64, error) {
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	kc, vc := rbt.codecs()
+	f := Fingerprint{pow: 1}
+	var (
+		buf []byte
+		h   uint64
+		err error
+	)
+	rbt.walk(func(n *node[K, V]) bool {
+		buf, h, err = hashEntry(buf, kc, vc, n.key, n.value)
+		if err != nil {
+			return false
+		}
+		f = f.combine(entryFingerprint(h))
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	return f.Sum(), nil
+}
@@ -0,0 +1,126 @@
+package rbtree
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func intEq(x, y int) bool {
+	return x == y
+}
+
+func intCmp(x, y int) int {
+	return x - y
+}
+
+func TestEqualAndCompare(t *testing.T) {
+	// 插入顺序不同，形状不同
+	a, b := NewRBTree[int, int](), NewRBTree[int, int]()
+	for i := 0; i < 100; i++ {
+		a.Put(i, i)
+		b.Put(99-i, 99-i)
+	}
+	if !Equal(a, b, intEq) || Compare(a, b, intCmp) != 0 || !Equal(a, a, intEq) {
+		t.Fatal("error: trees with same content should be equal")
+	}
+
+	b.Put(50, 0)
+	if Equal(a, b, intEq) || Compare(a, b, intCmp) != 1 || Compare(b, a, intCmp) != -1 {
+		t.Fatal("error: different value should compare by value")
+	}
+	b.Put(50, 50)
+	b.Put(100, 100)
+	if Equal(a, b, intEq) || Compare(a, b, intCmp) != -1 {
+		t.Fatal("error: prefix should be less")
+	}
+	b.Remove(100)
+	b.Remove(0)
+	// a 的第一个 key 0 小于 b 的第一个 key 1
+	if Compare(a, b, intCmp) != -1 || Compare(b, a, intCmp) != 1 {
+		t.Fatal("error: different key should compare by key")
+	}
+	empty := NewRBTree[int, int]()
+	if Compare(empty, NewRBTree[int, int](), intCmp) != 0 || Compare(empty, a, intCmp) != -1 {
+		t.Fatal("error: empty tree should be the smallest")
+	}
+}
+
+// 两个 goroutine 以相反的参数顺序比较时不会死锁
+func TestEqualConcurrent(t *testing.T) {
+	a, b := NewRBTree[int, int](), NewRBTree[int, int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				switch g {
+				case 0:
+					Equal(a, b, intEq)
+				case 1:
+					Equal(b, a, intEq)
+				case 2:
+					a.Put(i%10, i)
+				case 3:
+					b.Put(i%10, i)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func TestFingerprint(t *testing.T) {
+	a, b := NewRBTree[string, int](), NewRBTree[string, int]()
+	at := NewAugmentedTree[string, int, Fingerprint](FingerprintAggregator[string, int]{})
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		k := strings.Repeat("k", r.Intn(5)) + string(rune('a'+r.Intn(26)))
+		if r.Intn(3) == 0 {
+			a.Remove(k)
+			at.Remove(k)
+		} else {
+			a.Put(k, i)
+			at.Put(k, i)
+		}
+		fa, err := a.Fingerprint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := at.AggregateAll().Sum(); got != fa {
+			t.Fatalf("error: incremental fingerprint should %x, but get %x", fa, got)
+		}
+	}
+
+	// 内容相同、形状不同的树指纹相同
+	data, _ := a.MarshalBinary()
+	b.UnmarshalBinary(data)
+	fa, _ := a.Fingerprint()
+	fb, _ := b.Fingerprint()
+	if fa != fb {
+		t.Fatal("error: same content should have same fingerprint")
+	}
+	k, v, _ := b.Min()
+	b.Put(k, v+1)
+	if fb, _ = b.Fingerprint(); fa == fb {
+		t.Fatal("error: different value should change fingerprint")
+	}
+	b.Put(k, v)
+	b.Put("", 0)
+	if fb, _ = b.Fingerprint(); fa == fb {
+		t.Fatal("error: extra entry should change fingerprint")
+	}
+
+	// 范围的指纹等于只包含该范围的树的指纹
+	sub := NewRBTree[string, int]()
+	a.AscendRange("b", "kkd", func(k string, v int) bool {
+		sub.Put(k, v)
+		return true
+	})
+	fs, _ := sub.Fingerprint()
+	if got := at.Aggregate("b", "kkc\xff").Sum(); got != fs {
+		t.Fatalf("error: range fingerprint should %x, but get %x", fs, got)
+	}
+}
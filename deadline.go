@@ -0,0 +1,114 @@
+package rbtree
+
+import "time"
+
+type Deadline[ID comparable] struct {
+	ID ID
+	At time.Time
+}
+
+// DeadlineQueue 是按到期时间排序的定时队列
+// 到期时间（UnixNano）作为红黑树的 key，相同到期时间的 id 按调度顺序放在同一个节点中
+// index 记录 id 所在的节点，删除节点时不会移动其他节点的 key，所以节点指针一直有效，
+// Cancel 和 Reschedule 不需要按 key 查找节点，O(log n)
+type DeadlineQueue[ID comparable] struct {
+	tree  *RBTree[int64, []ID]
+	index map[ID]*node[int64, []ID]
+}
+
+func NewDeadlineQueue[ID comparable]() *DeadlineQueue[ID] {
+	return &DeadlineQueue[ID]{
+		tree:  NewRBTree[int64, []ID](),
+		index: make(map[ID]*node[int64, []ID]),
+	}
+}
+
+// Schedule 设置 id 在 at 到期，id 已存在时重新调度
+func (q *DeadlineQueue[ID]) Schedule(id ID, at time.Time) {
+	q.tree.mu.Lock()
+	defer q.tree.mu.Unlock()
+	q.cancel(id)
+	q.schedule(id, at.UnixNano())
+}
+
+// Reschedule 修改已存在的 id 的到期时间，id 不存在时返回 false
+func (q *DeadlineQueue[ID]) Reschedule(id ID, at time.Time) bool {
+	q.tree.mu.Lock()
+	defer q.tree.mu.Unlock()
+	if !q.cancel(id) {
+		return false
+	}
+	q.schedule(id, at.UnixNano())
+	return true
+}
+
+func (q *DeadlineQueue[ID]) Cancel(id ID) bool {
+	q.tree.mu.Lock()
+	defer q.tree.mu.Unlock()
+	return q.cancel(id)
+}
+
+// PopExpired 按到期时间顺序取出所有在 now 或之前到期的 id
+func (q *DeadlineQueue[ID]) PopExpired(now time.Time) []Deadline[ID] {
+	q.tree.mu.Lock()
+	defer q.tree.mu.Unlock()
+	deadline := now.UnixNano()
+	var res []Deadline[ID]
+	for n := q.tree.first(); n != nil && n.key <= deadline; n = q.tree.first() {
+		at := time.Unix(0, n.key)
+		for _, id := range n.value {
+			res = append(res, Deadline[ID]{id, at})
+			delete(q.index, id)
+		}
+		q.tree.deleteNode(n)
+	}
+	return res
+}
+
+// Peek 返回最早到期的 id
+func (q *DeadlineQueue[ID]) Peek() (d Deadline[ID], ok bool) {
+	q.tree.mu.RLock()
+	defer q.tree.mu.RUnlock()
+	n := q.tree.first()
+	if n == nil {
+		return d, false
+	}
+	return Deadline[ID]{n.value[0], time.Unix(0, n.key)}, true
+}
+
+func (q *DeadlineQueue[ID]) Len() int {
+	q.tree.mu.RLock()
+	defer q.tree.mu.RUnlock()
+	return len(q.index)
+}
+
+// 调用方需持有写锁
+func (q *DeadlineQueue[ID]) schedule(id ID, deadline int64) {
+	rbt := q.tree
+	if _, n := rbt.search(deadline); n != nil {
+		n.value = append(n.value, id)
+		q.index[id] = n
+		return
+	}
+	q.index[id] = rbt.insert(deadline, []ID{id})
+}
+
+// 调用方需持有写锁
+func (q *DeadlineQueue[ID]) cancel(id ID) bool {
+	n, ok := q.index[id]
+	if !ok {
+		return false
+	}
+	delete(q.index, id)
+	if len(n.value) == 1 {
+		q.tree.deleteNode(n)
+		return true
+	}
+	for i, v := range n.value {
+		if v == id {
+			n.value = append(n.value[:i], n.value[i+1:]...)
+			break
+		}
+	}
+	return true
+}
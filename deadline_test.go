@@ -0,0 +1,89 @@
+package rbtree
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestDeadlineQueue(t *testing.T) {
+	base := time.Unix(1000, 0)
+	at := func(s int) time.Time {
+		return base.Add(time.Duration(s) * time.Second)
+	}
+	q := NewDeadlineQueue[string]()
+	q.Schedule("a", at(5))
+	q.Schedule("b", at(1))
+	q.Schedule("c", at(5))
+	q.Schedule("d", at(3))
+	q.Schedule("e", at(9))
+
+	if d, _ := q.Peek(); d.ID != "b" || !d.At.Equal(at(1)) {
+		t.Fatalf("error: peek should b, but get %v", d)
+	}
+	if !q.Reschedule("d", at(7)) || q.Reschedule("x", at(1)) {
+		t.Fatal("error: only existing id can be rescheduled")
+	}
+	if !q.Cancel("a") || q.Cancel("a") {
+		t.Fatal("error: cancel a should only succeed once")
+	}
+	// 重复调度相当于重新设置到期时间
+	q.Schedule("e", at(6))
+
+	var got []string
+	for _, d := range q.PopExpired(at(6)) {
+		got = append(got, fmt.Sprintf("%v@%v", d.ID, d.At.Sub(base).Seconds()))
+	}
+	if fmt.Sprint(got) != "[b@1 c@5 e@6]" {
+		t.Fatalf("error: pop expired should [b@1 c@5 e@6], but get %v", got)
+	}
+	if q.Len() != 1 || len(q.PopExpired(at(6))) != 0 {
+		t.Fatal("error: only d should left")
+	}
+	if d := q.PopExpired(at(100)); len(d) != 1 || d[0].ID != "d" {
+		t.Fatalf("error: pop expired should [d], but get %v", d)
+	}
+	if _, ok := q.Peek(); ok || q.tree.Len() != 0 {
+		t.Fatal("error: queue should empty")
+	}
+}
+
+func TestDeadlineQueueRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	q := NewDeadlineQueue[int]()
+	want := make(map[int]int64)
+	base := time.Unix(0, 0)
+	for i := 0; i < 3000; i++ {
+		id := r.Intn(200)
+		switch r.Intn(4) {
+		case 0:
+			_, ok := want[id]
+			if q.Cancel(id) != ok {
+				t.Fatalf("error: cancel %v should return %v", id, ok)
+			}
+			delete(want, id)
+		case 1:
+			now := int64(r.Intn(100))
+			for _, d := range q.PopExpired(base.Add(time.Duration(now))) {
+				if w, ok := want[d.ID]; !ok || w != d.At.UnixNano() || w > now {
+					t.Fatalf("error: unexpected deadline %v", d)
+				}
+				delete(want, d.ID)
+			}
+			for id, w := range want {
+				if w <= now {
+					t.Fatalf("error: %v should expired", id)
+				}
+			}
+		default:
+			at := int64(r.Intn(1000))
+			q.Schedule(id, base.Add(time.Duration(at)))
+			want[id] = at
+		}
+		if q.Len() != len(want) {
+			t.Fatalf("error: queue len should %v, but get %v", len(want), q.Len())
+		}
+	}
+	checkRBTree(t, q.tree)
+}
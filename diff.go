@@ -0,0 +1,108 @@
+package rbtree
+
+import "golang.org/x/exp/constraints"
+
+// 差异的类型
+type DiffType byte
+
+const (
+	DiffAdded DiffType = iota
+	DiffRemoved
+	DiffChanged
+)
+
+func (t DiffType) String() string {
+	switch t {
+	case DiffAdded:
+		return "added"
+	case DiffRemoved:
+		return "removed"
+	case DiffChanged:
+		return "changed"
+	}
+	return "unknown"
+}
+
+// Change 描述 key 从旧树到新树的变化
+// DiffAdded: Old 为零值; DiffRemoved: New 为零值
+type Change[K any, V any] struct {
+	Type DiffType
+	Key  K
+	Old  V
+	New  V
+}
+
+// DiffIterator 按 key 升序返回两棵树之间的差异
+//
+//	it := Diff(old, new, eq)
+//	for it.Next() {
+//		c := it.Change()
+//	}
+//
+// 每次 Next 在两棵树的读锁下从上一个 key 之后重新定位，找到下一个差异后释放读锁，
+// 两次 Next 之间不持有任何锁，可以在循环中修改这两棵树（例如把每个 Change 应用到 old 上），
+// 也可以随时停止迭代。对已返回的 key 之前的修改不会再被看到，之后的修改会反映在后续的差异中
+type DiffIterator[K constraints.Ordered, V any] struct {
+	old, new *RBTree[K, V]
+	eq       func(x, y V) bool
+	started  bool
+	done     bool
+	change   Change[K, V]
+}
+
+// Diff 返回从 old 到 new 的差异迭代器，差异在 Next 中按需计算
+// eq 判断同一个 key 的 value 是否相同
+func Diff[K constraints.Ordered, V any](old, new *RBTree[K, V], eq func(x, y V) bool) *DiffIterator[K, V] {
+	return &DiffIterator[K, V]{old: old, new: new, eq: eq}
+}
+
+// 大于上一个返回的 key 的最小节点
+func (it *DiffIterator[K, V]) seek(rbt *RBTree[K, V]) *node[K, V] {
+	if !it.started {
+		return rbt.first()
+	}
+	n := rbt.ceiling(it.change.Key)
+	if n != nil && n.key == it.change.Key {
+		n = rbt.successor(n)
+	}
+	return n
+}
+
+// Next 前进到下一个差异，没有更多差异时返回 false
+// 每次调用 O(log n + 跳过的相同条目数)
+func (it *DiffIterator[K, V]) Next() bool {
+	if it.done {
+		return false
+	}
+	unlock := rlockPair(it.old, it.new)
+	defer unlock()
+	x, y := it.seek(it.old), it.seek(it.new)
+	for x != nil || y != nil {
+		switch {
+		case y == nil || x != nil && x.key < y.key:
+			it.change = Change[K, V]{Type: DiffRemoved, Key: x.key, Old: x.value}
+		case x == nil || y.key < x.key:
+			it.change = Change[K, V]{Type: DiffAdded, Key: y.key, New: y.value}
+		case !it.eq(x.value, y.value):
+			it.change = Change[K, V]{Type: DiffChanged, Key: x.key, Old: x.value, New: y.value}
+		default:
+			x, y = it.old.successor(x), it.new.successor(y)
+			continue
+		}
+		it.started = true
+		return true
+	}
+	it.Close()
+	return false
+}
+
+// Change 返回 Next 前进到的差异
+func (it *DiffIterator[K, V]) Change() Change[K, V] {
+	return it.change
+}
+
+// Close 停止迭代，之后 Next 总是返回 false
+func (it *DiffIterator[K, V]) Close() {
+	it.done = true
+	it.old, it.new, it.eq = nil, nil, nil
+}
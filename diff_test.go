@@ -0,0 +1,143 @@
+package rbtree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for round := 0; round < 50; round++ {
+		old, new := NewRBTree[int, int](), NewRBTree[int, int]()
+		mo, mn := map[int]int{}, map[int]int{}
+		for i := 0; i < 200; i++ {
+			k, v := r.Intn(300), r.Intn(3)
+			if r.Intn(2) == 0 {
+				old.Put(k, v)
+				mo[k] = v
+			}
+			if r.Intn(2) == 0 {
+				new.Put(k, v)
+				mn[k] = v
+			}
+		}
+
+		var changes []Change[int, int]
+		it := Diff(old, new, intEq)
+		for it.Next() {
+			changes = append(changes, it.Change())
+		}
+		for i, c := range changes {
+			if i > 0 && changes[i-1].Key >= c.Key {
+				t.Fatalf("error: changes should be in key order, but get %v", changes)
+			}
+			vo, oko := mo[c.Key]
+			vn, okn := mn[c.Key]
+			want := Change[int, int]{Key: c.Key, Old: vo, New: vn}
+			switch {
+			case !oko && okn:
+				want.Type = DiffAdded
+			case oko && !okn:
+				want.Type = DiffRemoved
+			case oko && okn && vo != vn:
+				want.Type = DiffChanged
+			default:
+				t.Fatalf("error: %v should not be a change", c)
+			}
+			if c != want {
+				t.Fatalf("error: change should %+v, but get %+v", want, c)
+			}
+		}
+		// 所有不同的 key 都被返回
+		cnt := 0
+		for k, vo := range mo {
+			if vn, ok := mn[k]; !ok || vn != vo {
+				cnt++
+			}
+		}
+		for k := range mn {
+			if _, ok := mo[k]; !ok {
+				cnt++
+			}
+		}
+		if cnt != len(changes) {
+			t.Fatalf("error: diff should return %v changes, but get %v", cnt, len(changes))
+		}
+
+	}
+}
+
+// 在循环中把差异应用到 old 上，结束后两棵树相同
+func TestDiffApply(t *testing.T) {
+	old, new := NewRBTree[int, int](), NewRBTree[int, int]()
+	for i := 0; i < 100; i++ {
+		old.Put(i, i)
+		new.Put(i+50, i%7)
+	}
+	it := Diff(old, new, intEq)
+	for it.Next() {
+		c := it.Change()
+		if c.Type == DiffRemoved {
+			old.Remove(c.Key)
+		} else {
+			old.Put(c.Key, c.New)
+		}
+	}
+	if !Equal(old, new, intEq) {
+		t.Fatal("error: applying all changes should make trees equal")
+	}
+
+	// 两次 Next 之间不持有锁，之后的写入会反映在后续的差异中
+	new.Put(1000, 0)
+	it = Diff(old, new, intEq)
+	if !it.Next() || it.Change() != (Change[int, int]{Type: DiffAdded, Key: 1000}) {
+		t.Fatalf("error: first change should add 1000, but get %+v", it.Change())
+	}
+	old.Put(1000, 0)
+	new.Put(2000, 1)
+	old.Put(-1, 0)
+	if !it.Next() || it.Change() != (Change[int, int]{Type: DiffAdded, Key: 2000, New: 1}) {
+		t.Fatalf("error: next change should add 2000, but get %+v", it.Change())
+	}
+	if it.Next() {
+		t.Fatalf("error: change before last key should not returned, but get %+v", it.Change())
+	}
+}
+
+func TestDiffClose(t *testing.T) {
+	old, new := NewRBTree[int, string](), NewRBTree[int, string]()
+	for i := 0; i < 10; i++ {
+		old.Put(i, "a")
+		new.Put(i+5, "b")
+	}
+	eq := func(x, y string) bool { return x == y }
+	it := Diff(old, new, eq)
+	if !it.Next() || it.Change() != (Change[int, string]{Type: DiffRemoved, Key: 0, Old: "a"}) {
+		t.Fatalf("error: first change get %+v", it.Change())
+	}
+	if !it.Next() || it.Change().Key != 1 {
+		t.Fatalf("error: second change should key 1, but get %+v", it.Change())
+	}
+	it.Close()
+	it.Close()
+	if it.Next() {
+		t.Fatal("error: closed iterator should not return changes")
+	}
+
+	// 同一棵树没有差异
+	it = Diff(old, old, eq)
+	if it.Next() {
+		t.Fatalf("error: diff with itself should be empty, but get %+v", it.Change())
+	}
+	it = Diff(NewRBTree[int, string](), new, eq)
+	n := 0
+	for it.Next() {
+		if it.Change().Type != DiffAdded {
+			t.Fatalf("error: diff from empty should only add, but get %+v", it.Change())
+		}
+		n++
+	}
+	if n != new.Len() {
+		t.Fatalf("error: diff from empty should add %v keys, but get %v", new.Len(), n)
+	}
+}
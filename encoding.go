@@ -0,0 +1,172 @@
+package rbtree
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// JSON 输出格式
+type JSONFormat byte
+
+const (
+	// [{"key":k1,"value":v1},{"key":k2,"value":v2}]
+	JSONArray JSONFormat = iota
+	// {"k1":v1,"k2":v2}，只支持底层类型为 string 的 key
+	JSONObject
+)
+
+var errObjectKey = errors.New("rbtree: JSON object format requires string keys")
+
+type jsonEntry[K any, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+// SetJSONFormat 设置 MarshalJSON 的输出格式，UnmarshalJSON 两种格式都能识别
+func (rbt *RBTree[K, V]) SetJSONFormat(f JSONFormat) {
+	rbt.mu.Lock()
+	defer rbt.mu.Unlock()
+	rbt.jsonFormat = f
+}
+
+func isStringKey[K any]() bool {
+	var k K
+	return reflect.TypeOf(&k).Elem().Kind() == reflect.String
+}
+
+// MarshalJSON 实现 json.Marshaler，两种格式都按 key 升序输出
+func (rbt *RBTree[K, V]) MarshalJSON() ([]byte, error) {
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+
+	object := rbt.jsonFormat == JSONObject
+	if object && !isStringKey[K]() {
+		return nil, errObjectKey
+	}
+	var (
+		buf bytes.Buffer
+		err error
+	)
+	if object {
+		buf.WriteByte('{')
+	} else {
+		buf.WriteByte('[')
+	}
+	rbt.walk(func(n *node[K, V]) bool {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		var b []byte
+		if object {
+			if b, err = json.Marshal(reflect.ValueOf(n.key).String()); err != nil {
+				return false
+			}
+			buf.Write(b)
+			buf.WriteByte(':')
+			b, err = json.Marshal(n.value)
+		} else {
+			b, err = json.Marshal(jsonEntry[K, V]{n.key, n.value})
+		}
+		buf.Write(b)
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if object {
+		buf.WriteByte('}')
+	} else {
+		buf.WriteByte(']')
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler，原有内容被替换
+// 数组格式中重复的 key 以最后出现的为准
+func (rbt *RBTree[K, V]) UnmarshalJSON(data []byte) error {
+	var entries []jsonEntry[K, V]
+	if b := bytes.TrimLeft(data, " \t\r\n"); len(b) > 0 && b[0] == '{' {
+		if !isStringKey[K]() {
+			return errObjectKey
+		}
+		m := make(map[K]V)
+		if err := json.Unmarshal(data, &m); err != nil {
+			return err
+		}
+		entries = make([]jsonEntry[K, V], 0, len(m))
+		for k, v := range m {
+			entries = append(entries, jsonEntry[K, V]{k, v})
+		}
+	} else if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	keys := make([]K, 0, len(entries))
+	values := make([]V, 0, len(entries))
+	for _, e := range entries {
+		if len(keys) > 0 && keys[len(keys)-1] == e.Key {
+			values[len(values)-1] = e.Value
+			continue
+		}
+		keys = append(keys, e.Key)
+		values = append(values, e.Value)
+	}
+
+	rbt.mu.Lock()
+	defer rbt.mu.Unlock()
+	rbt.build(keys, values)
+	return nil
+}
+
+type gobTree[K any, V any] struct {
+	Keys   []K
+	Values []V
+}
+
+// GobEncode 实现 gob.GobEncoder
+func (rbt *RBTree[K, V]) GobEncode() ([]byte, error) {
+	rbt.mu.RLock()
+	g := gobTree[K, V]{
+		Keys:   make([]K, 0, rbt.size),
+		Values: make([]V, 0, rbt.size),
+	}
+	rbt.walk(func(n *node[K, V]) bool {
+		g.Keys = append(g.Keys, n.key)
+		g.Values = append(g.Values, n.value)
+		return true
+	})
+	rbt.mu.RUnlock()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&g); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode 实现 gob.GobDecoder，原有内容被替换
+func (rbt *RBTree[K, V]) GobDecode(data []byte) error {
+	var g gobTree[K, V]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&g); err != nil {
+		return err
+	}
+	if len(g.Keys) != len(g.Values) {
+		return ErrInvalidData
+	}
+	for i := 1; i < len(g.Keys); i++ {
+		if g.Keys[i-1] >= g.Keys[i] {
+			return ErrUnsorted
+		}
+	}
+	rbt.mu.Lock()
+	defer rbt.mu.Unlock()
+	rbt.build(g.Keys, g.Values)
+	return nil
+}
@@ -0,0 +1,122 @@
+package rbtree
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+)
+
+func TestRBTreeMarshalJSON(t *testing.T) {
+	rbt := NewRBTree[int, string]()
+	rbt.Put(3, "c")
+	rbt.Put(1, "a")
+	rbt.Put(2, "b")
+	data, err := json.Marshal(rbt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"key":1,"value":"a"},{"key":2,"value":"b"},{"key":3,"value":"c"}]`
+	if string(data) != want {
+		t.Fatalf("error: json should %v, but get %v", want, string(data))
+	}
+
+	rbt.SetJSONFormat(JSONObject)
+	if _, err := json.Marshal(rbt); err == nil {
+		t.Fatal("error: object format with int keys should fail")
+	}
+
+	other := NewRBTree[int, string]()
+	if err := json.Unmarshal(data, other); err != nil {
+		t.Fatal(err)
+	}
+	checkRBTree(t, other)
+	if v, _ := other.Get(2); v != "b" {
+		t.Fatalf("error: get 2 should b, but get %v", v)
+	}
+}
+
+func TestRBTreeMarshalJSONObject(t *testing.T) {
+	type config struct {
+		Name  string
+		Hosts *RBTree[string, int]
+	}
+	c := config{Name: "test", Hosts: NewRBTree[string, int]()}
+	c.Hosts.SetJSONFormat(JSONObject)
+	c.Hosts.Put("b", 2)
+	c.Hosts.Put("c", 3)
+	c.Hosts.Put("a", 1)
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"Name":"test","Hosts":{"a":1,"b":2,"c":3}}`
+	if string(data) != want {
+		t.Fatalf("error: json should %v, but get %v", want, string(data))
+	}
+
+	var other config
+	if err := json.Unmarshal(data, &other); err != nil {
+		t.Fatal(err)
+	}
+	other.Hosts.Put("d", 4)
+	if checkRBTree(t, other.Hosts) != 4 {
+		t.Fatal("error: rbtree size should 4")
+	}
+	if v, _ := other.Hosts.Get("c"); v != 3 {
+		t.Fatalf("error: get c should 3, but get %v", v)
+	}
+}
+
+func TestRBTreeUnmarshalJSONUnsorted(t *testing.T) {
+	rbt := NewRBTree[int, int]()
+	data := `[{"key":3,"value":1},{"key":1,"value":1},{"key":3,"value":2}]`
+	if err := json.Unmarshal([]byte(data), rbt); err != nil {
+		t.Fatal(err)
+	}
+	if checkRBTree(t, rbt) != 2 {
+		t.Fatal("error: rbtree size should 2")
+	}
+	if v, _ := rbt.Get(3); v != 2 {
+		t.Fatalf("error: duplicated key should keep last value, but get %v", v)
+	}
+}
+
+func TestRBTreeGob(t *testing.T) {
+	type message struct {
+		ID    int
+		Index *RBTree[string, point]
+	}
+	m := message{ID: 1, Index: NewRBTree[string, point]()}
+	for i := 0; i < 100; i++ {
+		m.Index.Put(string(rune('a'+i%26))+string(rune('a'+i/26)), point{i, -i})
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&m); err != nil {
+		t.Fatal(err)
+	}
+	var other message
+	if err := gob.NewDecoder(&buf).Decode(&other); err != nil {
+		t.Fatal(err)
+	}
+	if checkRBTree(t, other.Index) != 100 {
+		t.Fatal("error: rbtree size should 100")
+	}
+	if v, _ := other.Index.Get("bc"); v != (point{53, -53}) {
+		t.Fatalf("error: get bc should {53 -53}, but get %v", v)
+	}
+}
+
+func TestRBTreeZeroValue(t *testing.T) {
+	var rbt RBTree[int, int]
+	if _, ok := rbt.Get(1); ok {
+		t.Fatal("error: empty rbtree should not contain 1")
+	}
+	rbt.Remove(1)
+	for i := 0; i < 100; i++ {
+		rbt.Put(i, i)
+	}
+	if checkRBTree(t, &rbt) != 100 {
+		t.Fatal("error: rbtree size should 100")
+	}
+}
@@ -0,0 +1,164 @@
+package rbtree
+
+import "golang.org/x/exp/constraints"
+
+// Interval 是闭区间 [Lo, Hi] 及其附带的值
+type Interval[K constraints.Ordered, V any] struct {
+	Lo, Hi K
+	Value  V
+}
+
+func (iv Interval[K, V]) overlaps(lo, hi K) bool {
+	return iv.Lo <= hi && lo <= iv.Hi
+}
+
+// 左端点相同的区间放在同一个节点中，按插入顺序排列
+// max 是整棵子树中所有区间的最大右端点
+type intervalBucket[K constraints.Ordered, V any] struct {
+	intervals []Interval[K, V]
+	max       K
+}
+
+// IntervalTree 是以区间左端点为 key 的红黑树，
+// 每个节点额外记录子树中的最大右端点，在插入、删除和旋转时维护
+type IntervalTree[K constraints.Ordered, V any] struct {
+	tree *RBTree[K, *intervalBucket[K, V]]
+	size int
+}
+
+func NewIntervalTree[K constraints.Ordered, V any]() *IntervalTree[K, V] {
+	it := &IntervalTree[K, V]{tree: NewRBTree[K, *intervalBucket[K, V]]()}
+	leaf := it.tree.leaf
+	it.tree.augment = func(n *node[K, *intervalBucket[K, V]]) {
+		b := n.value
+		b.max = b.intervals[0].Hi
+		for _, iv := range b.intervals[1:] {
+			if iv.Hi > b.max {
+				b.max = iv.Hi
+			}
+		}
+		if n.left != leaf && n.left.value.max > b.max {
+			b.max = n.left.value.max
+		}
+		if n.right != leaf && n.right.value.max > b.max {
+			b.max = n.right.value.max
+		}
+	}
+	return it
+}
+
+// Insert 插入区间 [lo, hi]，lo > hi 时交换两端
+func (it *IntervalTree[K, V]) Insert(lo, hi K, value V) {
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	rbt := it.tree
+	rbt.mu.Lock()
+	defer rbt.mu.Unlock()
+	iv := Interval[K, V]{lo, hi, value}
+	if _, n := rbt.search(lo); n != nil {
+		n.value.intervals = append(n.value.intervals, iv)
+		rbt.augmentPath(n)
+	} else {
+		rbt.insert(lo, &intervalBucket[K, V]{intervals: []Interval[K, V]{iv}})
+	}
+	it.size++
+}
+
+// Delete 删除一个 [lo, hi] 区间，存在多个时删除最早插入的
+func (it *IntervalTree[K, V]) Delete(lo, hi K) bool {
+	rbt := it.tree
+	rbt.mu.Lock()
+	defer rbt.mu.Unlock()
+	_, n := rbt.search(lo)
+	if n == nil {
+		return false
+	}
+	b := n.value
+	for i, iv := range b.intervals {
+		if iv.Hi != hi {
+			continue
+		}
+		if len(b.intervals) == 1 {
+			rbt.delete(lo)
+		} else {
+			b.intervals = append(b.intervals[:i], b.intervals[i+1:]...)
+			rbt.augmentPath(n)
+		}
+		it.size--
+		return true
+	}
+	return false
+}
+
+func (it *IntervalTree[K, V]) Len() int {
+	it.tree.mu.RLock()
+	defer it.tree.mu.RUnlock()
+	return it.size
+}
+
+// 按左端点升序访问与 [lo, hi] 相交的区间，fn 返回 false 时停止
+// 子树的最大右端点小于 lo 时整棵子树都可以跳过，左端点大于 hi 时右子树可以跳过，
+// 时间复杂度 O(log n + k)
+func (it *IntervalTree[K, V]) visit(lo, hi K, fn func(Interval[K, V]) bool) {
+	rbt := it.tree
+	var visit func(n *node[K, *intervalBucket[K, V]]) bool
+	visit = func(n *node[K, *intervalBucket[K, V]]) bool {
+		if n == nil || n == rbt.leaf || n.value.max < lo {
+			return true
+		}
+		if !visit(n.left) {
+			return false
+		}
+		if n.key > hi {
+			return true
+		}
+		for _, iv := range n.value.intervals {
+			if iv.Hi >= lo && !fn(iv) {
+				return false
+			}
+		}
+		return visit(n.right)
+	}
+	visit(rbt.root)
+}
+
+// Overlapping 返回所有与 [lo, hi] 相交的区间，按左端点升序排列
+func (it *IntervalTree[K, V]) Overlapping(lo, hi K) []Interval[K, V] {
+	it.tree.mu.RLock()
+	defer it.tree.mu.RUnlock()
+	var res []Interval[K, V]
+	it.visit(lo, hi, func(iv Interval[K, V]) bool {
+		res = append(res, iv)
+		return true
+	})
+	return res
+}
+
+// Stabbing 返回所有包含 point 的区间
+func (it *IntervalTree[K, V]) Stabbing(point K) []Interval[K, V] {
+	return it.Overlapping(point, point)
+}
+
+// AnyOverlap 返回任意一个与 [lo, hi] 相交的区间，O(log n)
+func (it *IntervalTree[K, V]) AnyOverlap(lo, hi K) (res Interval[K, V], ok bool) {
+	rbt := it.tree
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	// 左子树的最大右端点不小于 lo 时，如果左子树中没有相交的区间，
+	// 右子树中也不会有（右子树的左端点更大），所以只需要沿一条路径向下查找
+	n := rbt.root
+	for n != nil && n != rbt.leaf {
+		for _, iv := range n.value.intervals {
+			if iv.overlaps(lo, hi) {
+				return iv, true
+			}
+		}
+		if n.left != rbt.leaf && n.left.value.max >= lo {
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return res, false
+}
@@ -0,0 +1,113 @@
+package rbtree
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// 检查每个节点记录的子树最大右端点
+func checkIntervalTree(t *testing.T, it *IntervalTree[int, int]) {
+	t.Helper()
+	checkRBTree(t, it.tree)
+	var walk func(n *node[int, *intervalBucket[int, int]]) int
+	walk = func(n *node[int, *intervalBucket[int, int]]) int {
+		if n == it.tree.leaf {
+			return -1 << 31
+		}
+		m := max(walk(n.left), walk(n.right))
+		for _, iv := range n.value.intervals {
+			m = max(m, iv.Hi)
+		}
+		if n.value.max != m {
+			t.Fatalf("error: max of %v should %v, but get %v", n.key, m, n.value.max)
+		}
+		return m
+	}
+	if it.tree.root != nil {
+		walk(it.tree.root)
+	}
+}
+
+func TestIntervalTree(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	it := NewIntervalTree[int, int]()
+	var all []Interval[int, int]
+	for i := 0; i < 2000; i++ {
+		if len(all) > 0 && r.Intn(3) == 0 {
+			j := r.Intn(len(all))
+			if !it.Delete(all[j].Lo, all[j].Hi) {
+				t.Fatalf("error: delete %v should succeed", all[j])
+			}
+			// 相同的区间存在多个时删除的是最早插入的
+			for k := range all {
+				if all[k].Lo == all[j].Lo && all[k].Hi == all[j].Hi {
+					all = append(all[:k], all[k+1:]...)
+					break
+				}
+			}
+		} else {
+			lo := r.Intn(1000)
+			hi := lo + r.Intn(50)
+			it.Insert(lo, hi, i)
+			all = append(all, Interval[int, int]{lo, hi, i})
+		}
+		if i%50 == 0 {
+			checkIntervalTree(t, it)
+		}
+	}
+	checkIntervalTree(t, it)
+	if it.Len() != len(all) {
+		t.Fatalf("error: interval tree size should %v, but get %v", len(all), it.Len())
+	}
+
+	for i := 0; i < 200; i++ {
+		lo := r.Intn(1100)
+		hi := lo + r.Intn(20)
+		var want []int
+		for _, iv := range all {
+			if iv.overlaps(lo, hi) {
+				want = append(want, iv.Value)
+			}
+		}
+		var got []int
+		for _, iv := range it.Overlapping(lo, hi) {
+			got = append(got, iv.Value)
+		}
+		sort.Ints(want)
+		sort.Ints(got)
+		if len(want) != len(got) {
+			t.Fatalf("error: overlapping [%v, %v] should %v, but get %v", lo, hi, want, got)
+		}
+		for j := range want {
+			if want[j] != got[j] {
+				t.Fatalf("error: overlapping [%v, %v] should %v, but get %v", lo, hi, want, got)
+			}
+		}
+		iv, ok := it.AnyOverlap(lo, hi)
+		if ok != (len(want) > 0) || ok && !iv.overlaps(lo, hi) {
+			t.Fatalf("error: any overlap [%v, %v] get %v %v", lo, hi, iv, ok)
+		}
+	}
+}
+
+func TestIntervalTreeStabbing(t *testing.T) {
+	it := NewIntervalTree[int, string]()
+	it.Insert(1, 5, "a")
+	it.Insert(3, 3, "b")
+	it.Insert(10, 6, "c")
+	it.Insert(1, 2, "d")
+	got := it.Stabbing(3)
+	if len(got) != 2 || got[0].Value != "a" || got[1].Value != "b" {
+		t.Fatalf("error: stabbing 3 should [a b], but get %v", got)
+	}
+	if got := it.Stabbing(6); len(got) != 1 || got[0] != (Interval[int, string]{6, 10, "c"}) {
+		t.Fatalf("error: stabbing 6 should [c], but get %v", got)
+	}
+	if it.Delete(1, 3) {
+		t.Fatal("error: [1, 3] should not exist")
+	}
+	if _, ok := it.AnyOverlap(11, 20); ok {
+		t.Fatal("error: [11, 20] should not overlap")
+	}
+}
@@ -0,0 +1,393 @@
+package rbtree
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"golang.org/x/exp/constraints"
+)
+
+// merkleLeafSize 以内的范围直接交换 key 和哈希，不再继续拆分
+const merkleLeafSize = 8
+
+// 单条消息的最大长度，防止异常的长度前缀导致分配过大的内存
+const maxMerkleFrame = 1 << 26
+
+// 查询的类型
+const (
+	merkleSummaryQuery byte = iota
+	merkleEntriesQuery
+)
+
+type merkleValue[V any] struct {
+	value V
+	// key、value 编码后的哈希，写入时计算一次
+	hash uint64
+}
+
+// 子树内容的指纹和节点数
+type merkleSummary struct {
+	fp    Fingerprint
+	count int
+}
+
+type merkleAggregator[K any, V any] struct{}
+
+func (merkleAggregator[K, V]) Identity() merkleSummary {
+	return merkleSummary{fp: Fingerprint{pow: 1}}
+}
+
+func (merkleAggregator[K, V]) Combine(a, b merkleSummary) merkleSummary {
+	return merkleSummary{fp: a.fp.combine(b.fp), count: a.count + b.count}
+}
+
+func (merkleAggregator[K, V]) FromEntry(key K, value merkleValue[V]) merkleSummary {
+	return merkleSummary{fp: entryFingerprint(value.hash), count: 1}
+}
+
+// MerkleTree 是每个节点都记录子树内容哈希的红黑树，用于副本之间的反熵：
+// 两个副本通过 Diff / ServeDiff 递归地交换 key 范围的哈希，
+// 只深入哈希不同的范围，找出 d 个不同的 key 需要 O(d log n) 的数据量和 O(log n) 轮交互
+// 范围的哈希只取决于范围内的内容（与 Fingerprint 相同），与两边树的形状无关
+type MerkleTree[K constraints.Ordered, V any] struct {
+	at *AugmentedTree[K, merkleValue[V], merkleSummary]
+	kc Codec[K]
+	vc Codec[V]
+}
+
+// NewMerkleTree 创建 MerkleTree，编解码器为 nil 时使用默认编解码器
+// 参与比较的副本必须使用相同的编解码器
+func NewMerkleTree[K constraints.Ordered, V any](kc Codec[K], vc Codec[V]) *MerkleTree[K, V] {
+	if kc == nil {
+		kc = DefaultCodec[K]()
+	}
+	if vc == nil {
+		vc = DefaultCodec[V]()
+	}
+	return &MerkleTree[K, V]{
+		at: NewAugmentedTree[K, merkleValue[V], merkleSummary](merkleAggregator[K, V]{}),
+		kc: kc,
+		vc: vc,
+	}
+}
+
+// Put 插入或更新 key，key 或 value 无法编码时返回错误
+func (m *MerkleTree[K, V]) Put(key K, value V) error {
+	_, h, err := hashEntry(nil, m.kc, m.vc, key, value)
+	if err != nil {
+		return err
+	}
+	m.at.Put(key, merkleValue[V]{value: value, hash: h})
+	return nil
+}
+
+func (m *MerkleTree[K, V]) Get(key K) (value V, ok bool) {
+	v, ok := m.at.Get(key)
+	return v.value, ok
+}
+
+func (m *MerkleTree[K, V]) Remove(key K) bool {
+	return m.at.Remove(key)
+}
+
+func (m *MerkleTree[K, V]) Len() int {
+	return m.at.Len()
+}
+
+// RootHash 返回整棵树内容的哈希，O(1)，
+// 与使用相同编解码器的 RBTree.Fingerprint 结果相同
+func (m *MerkleTree[K, V]) RootHash() uint64 {
+	return m.at.AggregateAll().fp.Sum()
+}
+
+// 以下方法调用方需持有读锁
+
+func (m *MerkleTree[K, V]) count(n *node[K, *augmentedEntry[merkleValue[V], merkleSummary]]) int {
+	return m.at.subtree(n).count
+}
+
+// 范围内节点的聚合结果，coveredLo/coveredHi 表示子树已经满足下界/上界
+func (m *MerkleTree[K, V]) summary(r *View[K, *augmentedEntry[merkleValue[V], merkleSummary]],
+	n *node[K, *augmentedEntry[merkleValue[V], merkleSummary]], coveredLo, coveredHi bool) merkleSummary {
+	agg := m.at.agg
+	for n != nil && n != m.at.tree.leaf {
+		if coveredLo && coveredHi {
+			return n.value.agg
+		}
+		if !coveredLo && n.key < r.lo {
+			n = n.right
+		} else if !coveredHi && n.key >= r.hi {
+			n = n.left
+		} else {
+			left := m.summary(r, n.left, coveredLo, true)
+			right := m.summary(r, n.right, true, coveredHi)
+			return agg.Combine(agg.Combine(left, agg.FromEntry(n.key, n.value.value)), right)
+		}
+	}
+	return agg.Identity()
+}
+
+func (m *MerkleTree[K, V]) rangeSummary(r *View[K, *augmentedEntry[merkleValue[V], merkleSummary]]) merkleSummary {
+	return m.summary(r, m.at.tree.root, !r.hasLo, !r.hasHi)
+}
+
+// 小于 key 的节点数
+func (m *MerkleTree[K, V]) rank(key K) int {
+	res := 0
+	for n := m.at.tree.root; n != nil && n != m.at.tree.leaf; {
+		if n.key < key {
+			res += m.count(n.left) + 1
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return res
+}
+
+// 第 i 小的 key，i 从 0 开始
+func (m *MerkleTree[K, V]) keyAt(i int) K {
+	n := m.at.tree.root
+	for {
+		l := m.count(n.left)
+		if i < l {
+			n = n.left
+		} else if i == l {
+			return n.key
+		} else {
+			i -= l + 1
+			n = n.right
+		}
+	}
+}
+
+type merkleQuery[K constraints.Ordered, V any] struct {
+	kind byte
+	r    *View[K, *augmentedEntry[merkleValue[V], merkleSummary]]
+}
+
+// Diff 作为发起方与运行 ServeDiff 的另一个副本比较，返回按升序排列的、
+// 只存在于一方或 value 不同的 key
+// 比较过程中每个查询单独加读锁，两边在比较期间被修改时结果可能不准确
+func (m *MerkleTree[K, V]) Diff(rw io.ReadWriter) ([]K, error) {
+	br := bufio.NewReader(rw)
+	queries := []merkleQuery[K, V]{{kind: merkleSummaryQuery, r: &View[K, *augmentedEntry[merkleValue[V], merkleSummary]]{tree: m.at.tree}}}
+	var diff []K
+	for len(queries) > 0 {
+		msg, err := m.appendQueries(nil, queries)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeMerkleFrame(rw, msg); err != nil {
+			return nil, err
+		}
+		reply, err := readMerkleFrame(br)
+		if err != nil {
+			return nil, err
+		}
+		if queries, diff, err = m.handleReply(queries, reply, diff); err != nil {
+			return nil, err
+		}
+	}
+	// 空的查询列表表示结束
+	if err := writeMerkleFrame(rw, appendUvarint(nil, 0)); err != nil {
+		return nil, err
+	}
+	sort.Slice(diff, func(i, j int) bool { return diff[i] < diff[j] })
+	return diff, nil
+}
+
+func (m *MerkleTree[K, V]) appendQueries(buf []byte, queries []merkleQuery[K, V]) (_ []byte, err error) {
+	buf = appendUvarint(buf, uint64(len(queries)))
+	for _, q := range queries {
+		var flags byte
+		if q.r.hasLo {
+			flags |= 1
+		}
+		if q.r.hasHi {
+			flags |= 2
+		}
+		buf = append(buf, q.kind, flags)
+		if q.r.hasLo {
+			if buf, err = m.kc.Append(buf, q.r.lo); err != nil {
+				return nil, err
+			}
+		}
+		if q.r.hasHi {
+			if buf, err = m.kc.Append(buf, q.r.hi); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return buf, nil
+}
+
+// 根据回复计算下一轮的查询，并把找到的不同的 key 加入 diff
+func (m *MerkleTree[K, V]) handleReply(queries []merkleQuery[K, V], reply []byte, diff []K) ([]merkleQuery[K, V], []K, error) {
+	m.at.tree.mu.RLock()
+	defer m.at.tree.mu.RUnlock()
+	var next []merkleQuery[K, V]
+	for _, q := range queries {
+		if q.kind == merkleSummaryQuery {
+			cnt, n := binary.Uvarint(reply)
+			if n <= 0 || len(reply) < n+8 {
+				return nil, nil, ErrInvalidData
+			}
+			hash := binary.LittleEndian.Uint64(reply[n:])
+			reply = reply[n+8:]
+			local := m.rangeSummary(q.r)
+			if uint64(local.count) == cnt && local.fp.Sum() == hash {
+				continue
+			}
+			if local.count <= merkleLeafSize || cnt <= merkleLeafSize {
+				next = append(next, merkleQuery[K, V]{kind: merkleEntriesQuery, r: q.r})
+				continue
+			}
+			// 在本地范围的中位数处拆分
+			lo := 0
+			if q.r.hasLo {
+				lo = m.rank(q.r.lo)
+			}
+			mid := m.keyAt(lo + local.count/2)
+			next = append(next,
+				merkleQuery[K, V]{kind: merkleSummaryQuery, r: q.r.Head(mid)},
+				merkleQuery[K, V]{kind: merkleSummaryQuery, r: q.r.Tail(mid)})
+			continue
+		}
+
+		cnt, n := binary.Uvarint(reply)
+		if n <= 0 || cnt > uint64(len(reply)) {
+			return nil, nil, ErrInvalidData
+		}
+		reply = reply[n:]
+		remote := make(map[K]uint64, cnt)
+		for i := uint64(0); i < cnt; i++ {
+			k, n, err := m.kc.Decode(reply)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+			}
+			if len(reply) < n+8 {
+				return nil, nil, ErrInvalidData
+			}
+			remote[k] = binary.LittleEndian.Uint64(reply[n:])
+			reply = reply[n+8:]
+		}
+		q.r.walk(func(n *node[K, *augmentedEntry[merkleValue[V], merkleSummary]]) bool {
+			if h, ok := remote[n.key]; !ok || h != n.value.value.hash {
+				diff = append(diff, n.key)
+			}
+			delete(remote, n.key)
+			return true
+		})
+		for k := range remote {
+			diff = append(diff, k)
+		}
+	}
+	if len(reply) != 0 {
+		return nil, nil, ErrInvalidData
+	}
+	return next, diff, nil
+}
+
+// ServeDiff 作为响应方回答 Diff 发起方的查询，直到对方结束比较
+func (m *MerkleTree[K, V]) ServeDiff(rw io.ReadWriter) error {
+	br := bufio.NewReader(rw)
+	for {
+		msg, err := readMerkleFrame(br)
+		if err != nil {
+			return err
+		}
+		cnt, n := binary.Uvarint(msg)
+		if n <= 0 || cnt > uint64(len(msg)) {
+			return ErrInvalidData
+		}
+		if cnt == 0 {
+			return nil
+		}
+		reply, err := m.answer(msg[n:], int(cnt))
+		if err != nil {
+			return err
+		}
+		if err := writeMerkleFrame(rw, reply); err != nil {
+			return err
+		}
+	}
+}
+
+func (m *MerkleTree[K, V]) answer(msg []byte, cnt int) ([]byte, error) {
+	m.at.tree.mu.RLock()
+	defer m.at.tree.mu.RUnlock()
+	var reply []byte
+	for i := 0; i < cnt; i++ {
+		if len(msg) < 2 {
+			return nil, ErrInvalidData
+		}
+		kind, flags := msg[0], msg[1]
+		msg = msg[2:]
+		r := &View[K, *augmentedEntry[merkleValue[V], merkleSummary]]{tree: m.at.tree}
+		if flags&1 != 0 {
+			k, n, err := m.kc.Decode(msg)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+			}
+			r.lo, r.hasLo = k, true
+			msg = msg[n:]
+		}
+		if flags&2 != 0 {
+			k, n, err := m.kc.Decode(msg)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+			}
+			r.hi, r.hasHi = k, true
+			msg = msg[n:]
+		}
+
+		switch kind {
+		case merkleSummaryQuery:
+			s := m.rangeSummary(r)
+			reply = appendUvarint(reply, uint64(s.count))
+			reply = appendUint64(reply, s.fp.Sum())
+		case merkleEntriesQuery:
+			reply = appendUvarint(reply, uint64(m.rangeSummary(r).count))
+			var err error
+			r.walk(func(n *node[K, *augmentedEntry[merkleValue[V], merkleSummary]]) bool {
+				if reply, err = m.kc.Append(reply, n.key); err != nil {
+					return false
+				}
+				reply = appendUint64(reply, n.value.value.hash)
+				return true
+			})
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, errors.New("rbtree: unknown merkle query")
+		}
+	}
+	return reply, nil
+}
+
+func writeMerkleFrame(w io.Writer, msg []byte) error {
+	frame := appendUvarint(make([]byte, 0, len(msg)+binary.MaxVarintLen64), uint64(len(msg)))
+	_, err := w.Write(append(frame, msg...))
+	return err
+}
+
+func readMerkleFrame(r *bufio.Reader) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxMerkleFrame {
+		return nil, ErrInvalidData
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
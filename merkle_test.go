@@ -0,0 +1,127 @@
+package rbtree
+
+import (
+	"io"
+	"math/rand"
+	"net"
+	"sort"
+	"testing"
+)
+
+type countingConn struct {
+	net.Conn
+	written *int
+}
+
+func (c countingConn) Write(p []byte) (int, error) {
+	*c.written += len(p)
+	return c.Conn.Write(p)
+}
+
+// 在内存中连接两个副本，返回 a 相对 b 的差异和双方写入的字节数
+func merkleDiff(t *testing.T, a, b *MerkleTree[int, string]) ([]int, int) {
+	t.Helper()
+	ca, cb := net.Pipe()
+	defer ca.Close()
+	written := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- b.ServeDiff(countingConn{cb, &written})
+		cb.Close()
+	}()
+	diff, err := a.Diff(countingConn{ca, &written})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	return diff, written
+}
+
+func TestMerkleDiff(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	a := NewMerkleTree[int, string](nil, nil)
+	b := NewMerkleTree[int, string](nil, nil)
+	plain := NewRBTree[int, string]()
+	// 插入顺序不同，两边树的形状不同
+	for _, k := range r.Perm(10000) {
+		a.Put(k, "v")
+		plain.Put(k, "v")
+	}
+	for _, k := range r.Perm(10000) {
+		b.Put(k, "v")
+	}
+	if fp, _ := plain.Fingerprint(); a.RootHash() != b.RootHash() || a.RootHash() != fp {
+		t.Fatal("error: same content should have same root hash")
+	}
+	diff, full := merkleDiff(t, a, b)
+	if len(diff) != 0 {
+		t.Fatalf("error: identical replicas should have no diff, but get %v", diff)
+	}
+
+	want := map[int]bool{}
+	for i := 0; i < 10; i++ {
+		k := r.Intn(12000)
+		switch i % 3 {
+		case 0:
+			a.Put(k, "changed")
+		case 1:
+			b.Put(k, "new")
+		case 2:
+			a.Remove(k)
+		}
+		va, oka := a.Get(k)
+		vb, okb := b.Get(k)
+		want[k] = oka != okb || va != vb
+	}
+	var wantKeys []int
+	for k, d := range want {
+		if d {
+			wantKeys = append(wantKeys, k)
+		}
+	}
+	sort.Ints(wantKeys)
+
+	diff, written := merkleDiff(t, a, b)
+	if len(diff) != len(wantKeys) {
+		t.Fatalf("error: diff should %v, but get %v", wantKeys, diff)
+	}
+	for i := range diff {
+		if diff[i] != wantKeys[i] {
+			t.Fatalf("error: diff should %v, but get %v", wantKeys, diff)
+		}
+	}
+	// 只交换了与差异相关的范围
+	if written > 20000 || written < full {
+		t.Fatalf("error: diff of %v keys wrote %v bytes", len(diff), written)
+	}
+}
+
+func TestMerkleDiffEmpty(t *testing.T) {
+	a := NewMerkleTree[int, string](nil, nil)
+	b := NewMerkleTree[int, string](nil, nil)
+	for i := 0; i < 100; i++ {
+		b.Put(i, "v")
+	}
+	if diff, _ := merkleDiff(t, a, b); len(diff) != 100 || diff[0] != 0 || diff[99] != 99 {
+		t.Fatalf("error: diff with empty replica should return all keys, but get %v", diff)
+	}
+	if diff, _ := merkleDiff(t, b, a); len(diff) != 100 {
+		t.Fatalf("error: diff with empty replica should return all keys, but get %v", diff)
+	}
+}
+
+func TestMerkleServeDiffInvalid(t *testing.T) {
+	ca, cb := net.Pipe()
+	defer ca.Close()
+	done := make(chan error, 1)
+	go func() {
+		done <- NewMerkleTree[int, string](nil, nil).ServeDiff(cb)
+	}()
+	// 1 个查询，类型未知
+	ca.Write([]byte{3, 1, 9, 0})
+	if err := <-done; err == nil || err == io.EOF {
+		t.Fatalf("error: unknown query should fail, but get %v", err)
+	}
+}
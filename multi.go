@@ -0,0 +1,111 @@
+package rbtree
+
+import "golang.org/x/exp/constraints"
+
+// MultiTree 是允许 key 重复的红黑树
+// 相同 key 的 value 放在同一个节点中，按插入顺序排列
+type MultiTree[K constraints.Ordered, V any] struct {
+	tree *RBTree[K, []V]
+	size int
+}
+
+func NewMultiTree[K constraints.Ordered, V any]() *MultiTree[K, V] {
+	return &MultiTree[K, V]{tree: NewRBTree[K, []V]()}
+}
+
+// Put 追加一个 value，不会覆盖 key 已有的 value
+func (mt *MultiTree[K, V]) Put(key K, value V) {
+	rbt := mt.tree
+	rbt.mu.Lock()
+	defer rbt.mu.Unlock()
+	if _, n := rbt.search(key); n != nil {
+		n.value = append(n.value, value)
+	} else {
+		rbt.insert(key, []V{value})
+	}
+	mt.size++
+}
+
+// GetAll 按插入顺序返回 key 的所有 value
+func (mt *MultiTree[K, V]) GetAll(key K) []V {
+	rbt := mt.tree
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	_, n := rbt.search(key)
+	if n == nil {
+		return nil
+	}
+	return append([]V(nil), n.value...)
+}
+
+func (mt *MultiTree[K, V]) Count(key K) int {
+	rbt := mt.tree
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	_, n := rbt.search(key)
+	if n == nil {
+		return 0
+	}
+	return len(n.value)
+}
+
+// RemoveOne 删除 key 的 value 中第一个满足 pred 的，pred 为 nil 时删除最早插入的
+func (mt *MultiTree[K, V]) RemoveOne(key K, pred func(V) bool) bool {
+	rbt := mt.tree
+	rbt.mu.Lock()
+	defer rbt.mu.Unlock()
+	_, n := rbt.search(key)
+	if n == nil {
+		return false
+	}
+	for i, v := range n.value {
+		if pred != nil && !pred(v) {
+			continue
+		}
+		if len(n.value) == 1 {
+			rbt.delete(key)
+		} else {
+			copy(n.value[i:], n.value[i+1:])
+			var zero V
+			n.value[len(n.value)-1] = zero
+			n.value = n.value[:len(n.value)-1]
+		}
+		mt.size--
+		return true
+	}
+	return false
+}
+
+// RemoveAll 删除 key 的所有 value，返回删除的个数
+func (mt *MultiTree[K, V]) RemoveAll(key K) int {
+	rbt := mt.tree
+	rbt.mu.Lock()
+	defer rbt.mu.Unlock()
+	_, n := rbt.search(key)
+	if n == nil {
+		return 0
+	}
+	cnt := len(n.value)
+	rbt.delete(key)
+	mt.size -= cnt
+	return cnt
+}
+
+// Len 返回 value 的总数
+func (mt *MultiTree[K, V]) Len() int {
+	mt.tree.mu.RLock()
+	defer mt.tree.mu.RUnlock()
+	return mt.size
+}
+
+// Ascend 按 key 升序遍历，相同 key 的 value 按插入顺序访问，fn 返回 false 时停止
+func (mt *MultiTree[K, V]) Ascend(fn func(key K, value V) bool) {
+	mt.tree.Ascend(func(key K, values []V) bool {
+		for _, v := range values {
+			if !fn(key, v) {
+				return false
+			}
+		}
+		return true
+	})
+}
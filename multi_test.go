@@ -0,0 +1,74 @@
+package rbtree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMultiTree(t *testing.T) {
+	mt := NewMultiTree[int, string]()
+	mt.Put(2, "a")
+	mt.Put(1, "b")
+	mt.Put(2, "c")
+	mt.Put(2, "d")
+	mt.Put(3, "e")
+
+	if got := mt.GetAll(2); !reflect.DeepEqual(got, []string{"a", "c", "d"}) {
+		t.Fatalf("error: get all 2 should [a c d], but get %v", got)
+	}
+	if mt.Count(2) != 3 || mt.Count(4) != 0 || mt.Len() != 5 {
+		t.Fatal("error: count of 2 should 3 and len should 5")
+	}
+
+	var keys []int
+	var values []string
+	mt.Ascend(func(k int, v string) bool {
+		keys = append(keys, k)
+		values = append(values, v)
+		return true
+	})
+	if !reflect.DeepEqual(keys, []int{1, 2, 2, 2, 3}) || !reflect.DeepEqual(values, []string{"b", "a", "c", "d", "e"}) {
+		t.Fatalf("error: ascend get %v %v", keys, values)
+	}
+
+	if !mt.RemoveOne(2, func(v string) bool { return v == "c" }) {
+		t.Fatal("error: remove c should succeed")
+	}
+	if mt.RemoveOne(2, func(v string) bool { return v == "x" }) {
+		t.Fatal("error: remove x should fail")
+	}
+	if !mt.RemoveOne(2, nil) {
+		t.Fatal("error: remove first value of 2 should succeed")
+	}
+	if got := mt.GetAll(2); !reflect.DeepEqual(got, []string{"d"}) {
+		t.Fatalf("error: get all 2 should [d], but get %v", got)
+	}
+	mt.RemoveOne(1, nil)
+	if mt.GetAll(1) != nil || mt.tree.Len() != 2 {
+		t.Fatal("error: key 1 should removed")
+	}
+
+	mt.Put(3, "f")
+	if mt.RemoveAll(3) != 2 || mt.RemoveAll(3) != 0 {
+		t.Fatal("error: remove all 3 should remove 2 values")
+	}
+	if mt.Len() != 1 {
+		t.Fatalf("error: len should 1, but get %v", mt.Len())
+	}
+	checkRBTree(t, mt.tree)
+}
+
+func TestMultiTreeAscendStop(t *testing.T) {
+	mt := NewMultiTree[int, int]()
+	for i := 0; i < 10; i++ {
+		mt.Put(i/3, i)
+	}
+	var got []int
+	mt.Ascend(func(_, v int) bool {
+		got = append(got, v)
+		return v < 4
+	})
+	if !reflect.DeepEqual(got, []int{0, 1, 2, 3, 4}) {
+		t.Fatalf("error: ascend should stop at 4, but get %v", got)
+	}
+}
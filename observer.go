@@ -0,0 +1,141 @@
+package rbtree
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+)
+
+// 触发调整的操作
+type Op byte
+
+const (
+	OpInsert Op = iota
+	OpDelete
+)
+
+func (op Op) String() string {
+	if op == OpInsert {
+		return "insert"
+	}
+	return "delete"
+}
+
+// OpStats 是一次插入或删除中调整红黑树的开销
+type OpStats struct {
+	Op             Op
+	LeftRotations  int
+	RightRotations int
+	// 节点颜色实际发生变化的次数
+	Recolors int
+	// insertAdjust / deleteAdjust 循环的次数
+	Steps int
+}
+
+// Observer 在每次插入新节点或删除节点之后被调用，调用时持有写锁，
+// 实现中不能访问红黑树，并且应当尽快返回
+// 更新已存在的 key 不会调用 Observer
+type Observer interface {
+	Observe(s OpStats)
+}
+
+// SetObserver 设置 Observer，传 nil 取消
+// 没有 Observer 时调整过程中只多一次 nil 判断
+func (rbt *RBTree[K, V]) SetObserver(o Observer) {
+	rbt.mu.Lock()
+	defer rbt.mu.Unlock()
+	rbt.observer = o
+}
+
+func (rbt *RBTree[K, V]) observe(op Op) {
+	if rbt.observer == nil {
+		return
+	}
+	rbt.opStats.Op = op
+	rbt.observer.Observe(rbt.opStats)
+	rbt.opStats = OpStats{}
+}
+
+func (rbt *RBTree[K, V]) changeColor(n *node[K, V]) {
+	if rbt.observer != nil {
+		rbt.opStats.Recolors++
+	}
+	n.changeColor()
+	rbt.trace(TraceRecolor, "", n)
+}
+
+func (rbt *RBTree[K, V]) setColor(n *node[K, V], c color) {
+	if n.color == c {
+		return
+	}
+	if rbt.observer != nil {
+		rbt.opStats.Recolors++
+	}
+	n.color = c
+	rbt.trace(TraceRecolor, "", n)
+}
+
+// Counters 是累计各项开销的 Observer，可以同时安装到多棵树上
+// 实现了 expvar.Var，可以直接用 expvar.Publish 导出
+type Counters struct {
+	ops, rotations, recolors, steps [2]uint64
+}
+
+func (c *Counters) Observe(s OpStats) {
+	atomic.AddUint64(&c.ops[s.Op], 1)
+	atomic.AddUint64(&c.rotations[s.Op], uint64(s.LeftRotations+s.RightRotations))
+	atomic.AddUint64(&c.recolors[s.Op], uint64(s.Recolors))
+	atomic.AddUint64(&c.steps[s.Op], uint64(s.Steps))
+}
+
+// Total 返回 op 的累计开销
+func (c *Counters) Total(op Op) (ops, rotations, recolors, steps uint64) {
+	return atomic.LoadUint64(&c.ops[op]),
+		atomic.LoadUint64(&c.rotations[op]),
+		atomic.LoadUint64(&c.recolors[op]),
+		atomic.LoadUint64(&c.steps[op])
+}
+
+func (c *Counters) metrics() []struct {
+	name, help string
+	values     *[2]uint64
+} {
+	return []struct {
+		name, help string
+		values     *[2]uint64
+	}{
+		{"rbtree_operations_total", "Number of inserts and deletes.", &c.ops},
+		{"rbtree_rotations_total", "Number of rotations while rebalancing.", &c.rotations},
+		{"rbtree_recolors_total", "Number of node color changes while rebalancing.", &c.recolors},
+		{"rbtree_adjust_steps_total", "Number of fix-up loop iterations.", &c.steps},
+	}
+}
+
+// String 以 JSON 格式输出，实现 expvar.Var
+func (c *Counters) String() string {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, m := range c.metrics() {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		fmt.Fprintf(&sb, `"%s":{"insert":%d,"delete":%d}`, m.name,
+			atomic.LoadUint64(&m.values[OpInsert]), atomic.LoadUint64(&m.values[OpDelete]))
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// WriteText 以 Prometheus 文本格式输出
+func (c *Counters) WriteText(w io.Writer) error {
+	var sb strings.Builder
+	for _, m := range c.metrics() {
+		fmt.Fprintf(&sb, "# HELP %s %s\n# TYPE %s counter\n", m.name, m.help, m.name)
+		for _, op := range []Op{OpInsert, OpDelete} {
+			fmt.Fprintf(&sb, "%s{op=%q} %d\n", m.name, op.String(), atomic.LoadUint64(&m.values[op]))
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
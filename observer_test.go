@@ -0,0 +1,86 @@
+package rbtree
+
+import (
+	"encoding/json"
+	"expvar"
+	"strings"
+	"testing"
+)
+
+var _ expvar.Var = (*Counters)(nil)
+
+type recordObserver []OpStats
+
+func (r *recordObserver) Observe(s OpStats) {
+	*r = append(*r, s)
+}
+
+func TestRBTreeObserver(t *testing.T) {
+	rbt := NewRBTree[int, int]()
+	var rec recordObserver
+	rbt.SetObserver(&rec)
+	rbt.Put(10, 0)
+	rbt.Put(5, 0)
+	// case 3.1.1: 祖父节点右旋，父节点和祖父节点变色
+	rbt.Put(4, 0)
+	rbt.Put(4, 1)
+	rbt.Remove(10)
+	rbt.Remove(5)
+	want := []OpStats{
+		{Op: OpInsert},
+		{Op: OpInsert},
+		{Op: OpInsert, RightRotations: 1, Recolors: 2, Steps: 1},
+		// 删除红色节点 10，无需调整
+		{Op: OpDelete},
+		// 删除黑色节点 5，顶替它的红色子节点 4 变为黑色
+		{Op: OpDelete, Recolors: 1},
+	}
+	if len(rec) != len(want) {
+		t.Fatalf("error: observer should called %v times, but get %v", len(want), rec)
+	}
+	for i := range want {
+		if rec[i] != want[i] {
+			t.Fatalf("error: stats %v should %+v, but get %+v", i, want[i], rec[i])
+		}
+	}
+
+	rbt.SetObserver(nil)
+	rbt.Put(1, 1)
+	if len(rec) != len(want) {
+		t.Fatal("error: removed observer should not called")
+	}
+}
+
+func TestCounters(t *testing.T) {
+	var c Counters
+	rbt := NewRBTree[int, int]()
+	rbt.SetObserver(&c)
+	for i := 0; i < 1000; i++ {
+		rbt.Put(i, i)
+	}
+	for i := 0; i < 500; i++ {
+		rbt.Remove(i)
+	}
+	ops, rotations, recolors, _ := c.Total(OpInsert)
+	if ops != 1000 || rotations == 0 || recolors == 0 {
+		t.Fatalf("error: insert totals get %v %v %v", ops, rotations, recolors)
+	}
+	if ops, _, _, _ := c.Total(OpDelete); ops != 500 {
+		t.Fatalf("error: delete ops should 500, but get %v", ops)
+	}
+
+	var v map[string]map[string]uint64
+	if err := json.Unmarshal([]byte(c.String()), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v["rbtree_operations_total"]["insert"] != 1000 || v["rbtree_rotations_total"]["insert"] != rotations {
+		t.Fatalf("error: json get %v", c.String())
+	}
+
+	var sb strings.Builder
+	c.WriteText(&sb)
+	if !strings.Contains(sb.String(), "# TYPE rbtree_operations_total counter\n") ||
+		!strings.Contains(sb.String(), "rbtree_operations_total{op=\"delete\"} 500\n") {
+		t.Fatalf("error: text get %v", sb.String())
+	}
+}
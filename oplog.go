@@ -0,0 +1,250 @@
+package rbtree
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/exp/constraints"
+)
+
+// 操作日志格式：
+// magic "RBL" | version(1 byte) | 若干条记录
+// 记录：op(1 byte，0 为 Put，1 为 Remove) | key | value（只有 Put 有）
+// key、value 的编码与 MarshalBinary 相同
+const opLogVersion = 1
+
+var opLogMagic = []byte("RBL")
+
+// OpRecord 是一次 Put（OpInsert）或 Remove（OpDelete）
+type OpRecord[K constraints.Ordered, V any] struct {
+	Op    Op
+	Key   K
+	Value V
+}
+
+func (r OpRecord[K, V]) String() string {
+	if r.Op == OpDelete {
+		return fmt.Sprintf("remove %v", r.Key)
+	}
+	return fmt.Sprintf("put %v %v", r.Key, r.Value)
+}
+
+// Recorder 在修改红黑树之前把操作写入日志，用于复现问题
+// 操作 panic 时会先把已缓冲的日志写出再继续 panic
+type Recorder[K constraints.Ordered, V any] struct {
+	tree *RBTree[K, V]
+	w    *bufio.Writer
+	kc   Codec[K]
+	vc   Codec[V]
+	buf  []byte
+	err  error
+}
+
+// NewRecorder 写入日志头，使用 rbt 通过 SetCodec 设置的编解码器
+func NewRecorder[K constraints.Ordered, V any](rbt *RBTree[K, V], w io.Writer) (*Recorder[K, V], error) {
+	rbt.mu.RLock()
+	kc, vc := rbt.codecs()
+	rbt.mu.RUnlock()
+	r := &Recorder[K, V]{tree: rbt, w: bufio.NewWriter(w), kc: kc, vc: vc}
+	if _, err := r.w.Write(append(opLogMagic[:len(opLogMagic):len(opLogMagic)], opLogVersion)); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Recorder[K, V]) record(op Op, key K, value V) {
+	if r.err != nil {
+		return
+	}
+	buf := append(r.buf[:0], byte(op))
+	buf, r.err = r.kc.Append(buf, key)
+	if r.err == nil && op == OpInsert {
+		buf, r.err = r.vc.Append(buf, value)
+	}
+	if r.err == nil {
+		_, r.err = r.w.Write(buf)
+	}
+	r.buf = buf
+}
+
+func (r *Recorder[K, V]) flushOnPanic() {
+	if e := recover(); e != nil {
+		r.w.Flush()
+		panic(e)
+	}
+}
+
+func (r *Recorder[K, V]) Put(key K, value V) {
+	r.record(OpInsert, key, value)
+	defer r.flushOnPanic()
+	r.tree.Put(key, value)
+}
+
+func (r *Recorder[K, V]) Remove(key K) bool {
+	var zero V
+	r.record(OpDelete, key, zero)
+	defer r.flushOnPanic()
+	return r.tree.Remove(key)
+}
+
+func (r *Recorder[K, V]) Get(key K) (V, bool) {
+	return r.tree.Get(key)
+}
+
+func (r *Recorder[K, V]) Tree() *RBTree[K, V] {
+	return r.tree
+}
+
+// Flush 写出缓冲的日志，返回记录过程中的第一个错误
+func (r *Recorder[K, V]) Flush() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.w.Flush()
+}
+
+// ReadOpLog 读取 Recorder 写入的日志，编解码器为 nil 时使用默认编解码器
+// 日志末尾不完整时返回已读取的操作和 ErrInvalidData
+func ReadOpLog[K constraints.Ordered, V any](r io.Reader, kc Codec[K], vc Codec[V]) ([]OpRecord[K, V], error) {
+	if kc == nil {
+		kc = DefaultCodec[K]()
+	}
+	if vc == nil {
+		vc = DefaultCodec[V]()
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < len(opLogMagic)+1 || string(data[:len(opLogMagic)]) != string(opLogMagic) {
+		return nil, ErrInvalidData
+	}
+	if data[len(opLogMagic)] != opLogVersion {
+		return nil, errors.New("rbtree: unsupported op log version")
+	}
+	data = data[len(opLogMagic)+1:]
+
+	var ops []OpRecord[K, V]
+	for len(data) > 0 {
+		rec := OpRecord[K, V]{Op: Op(data[0])}
+		if rec.Op != OpInsert && rec.Op != OpDelete {
+			return ops, ErrInvalidData
+		}
+		data = data[1:]
+		k, n, err := kc.Decode(data)
+		if err != nil {
+			return ops, fmt.Errorf("%w: %v", ErrInvalidData, err)
+		}
+		rec.Key = k
+		data = data[n:]
+		if rec.Op == OpInsert {
+			v, n, err := vc.Decode(data)
+			if err != nil {
+				return ops, fmt.Errorf("%w: %v", ErrInvalidData, err)
+			}
+			rec.Value = v
+			data = data[n:]
+		}
+		ops = append(ops, rec)
+	}
+	return ops, nil
+}
+
+// ReplayError 是重放时第一个失败的操作
+type ReplayError[K constraints.Ordered, V any] struct {
+	Index int
+	Op    OpRecord[K, V]
+	Err   error
+}
+
+func (e *ReplayError[K, V]) Error() string {
+	return fmt.Sprintf("rbtree: op %d (%v): %v", e.Index, e.Op, e.Err)
+}
+
+func (e *ReplayError[K, V]) Unwrap() error {
+	return e.Err
+}
+
+// Replay 在 rbt 上依次执行 ops，validate 为 true 时每一步之后调用 Validate
+// 操作中的 panic 也作为错误返回
+func Replay[K constraints.Ordered, V any](rbt *RBTree[K, V], ops []OpRecord[K, V], validate bool) (err error) {
+	i := 0
+	defer func() {
+		if e := recover(); e != nil {
+			err = &ReplayError[K, V]{Index: i, Op: ops[i], Err: fmt.Errorf("panic: %v", e)}
+		}
+	}()
+	for ; i < len(ops); i++ {
+		if ops[i].Op == OpDelete {
+			rbt.Remove(ops[i].Key)
+		} else {
+			rbt.Put(ops[i].Key, ops[i].Value)
+		}
+		if validate {
+			if err := rbt.Validate(); err != nil {
+				return &ReplayError[K, V]{Index: i, Op: ops[i], Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+// Minimize 用 delta debugging（ddmin）缩减一组仍然能让 fails 返回 true 的操作，
+// 结果是 1-minimal 的：去掉其中任意一个操作都不再失败
+// fails 为 nil 时在新建的红黑树上重放并检查 Validate
+// ops 本身不满足 fails 时原样返回
+func Minimize[K constraints.Ordered, V any](ops []OpRecord[K, V], fails func(ops []OpRecord[K, V]) bool) []OpRecord[K, V] {
+	if fails == nil {
+		fails = func(ops []OpRecord[K, V]) bool {
+			return Replay(NewRBTree[K, V](), ops, true) != nil
+		}
+	}
+	ops = append([]OpRecord[K, V](nil), ops...)
+	if !fails(ops) {
+		return ops
+	}
+	n := 2
+	for len(ops) >= 2 {
+		size := (len(ops) + n - 1) / n
+		reduced := false
+		// 先尝试只保留其中一块
+		for lo := 0; lo < len(ops) && !reduced; lo += size {
+			hi := lo + size
+			if hi > len(ops) {
+				hi = len(ops)
+			}
+			if sub := ops[lo:hi]; len(sub) < len(ops) && fails(sub) {
+				ops = append([]OpRecord[K, V](nil), sub...)
+				n = 2
+				reduced = true
+			}
+		}
+		// 再尝试去掉其中一块
+		for lo := 0; lo < len(ops) && !reduced; lo += size {
+			hi := lo + size
+			if hi > len(ops) {
+				hi = len(ops)
+			}
+			rest := append(append([]OpRecord[K, V](nil), ops[:lo]...), ops[hi:]...)
+			if fails(rest) {
+				ops = rest
+				if n > 2 {
+					n--
+				}
+				reduced = true
+			}
+		}
+		if !reduced {
+			if n >= len(ops) {
+				break
+			}
+			n *= 2
+			if n > len(ops) {
+				n = len(ops)
+			}
+		}
+	}
+	return ops
+}
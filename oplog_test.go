@@ -0,0 +1,122 @@
+package rbtree
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestRecorderAndReplay(t *testing.T) {
+	var buf bytes.Buffer
+	rec, err := NewRecorder(NewRBTree[int, string](), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		k := r.Intn(100)
+		if r.Intn(3) == 0 {
+			rec.Remove(k)
+		} else {
+			rec.Put(k, string(rune('a'+i%26)))
+		}
+	}
+	if err := rec.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	ops, err := ReadOpLog[int, string](bytes.NewReader(buf.Bytes()), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 1000 {
+		t.Fatalf("error: op log should have 1000 ops, but get %v", len(ops))
+	}
+	rbt := NewRBTree[int, string]()
+	if err := Replay(rbt, ops, true); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := rec.Tree().MarshalBinary()
+	got, _ := rbt.MarshalBinary()
+	if !bytes.Equal(want, got) {
+		t.Fatal("error: replayed tree should equal the recorded tree")
+	}
+
+	// 末尾不完整
+	ops, err = ReadOpLog[int, string](bytes.NewReader(buf.Bytes()[:buf.Len()-1]), nil, nil)
+	if !errors.Is(err, ErrInvalidData) || len(ops) != 999 {
+		t.Fatalf("error: truncated log should return 999 ops and ErrInvalidData, but get %v %v", len(ops), err)
+	}
+	if _, err := ReadOpLog[int, string](bytes.NewReader([]byte("RBT\x01")), nil, nil); err != ErrInvalidData {
+		t.Fatalf("error: bad magic should fail, but get %v", err)
+	}
+}
+
+func TestRecorderFlushOnPanic(t *testing.T) {
+	var buf bytes.Buffer
+	rbt := NewRBTree[int, int]()
+	rec, _ := NewRecorder(rbt, &buf)
+	rec.Put(1, 1)
+	rbt.augment = func(n *node[int, int]) {
+		if n.key == 2 {
+			panic("bug")
+		}
+	}
+	func() {
+		defer func() { recover() }()
+		rec.Put(2, 2)
+	}()
+	ops, err := ReadOpLog[int, int](&buf, nil, nil)
+	if err != nil || len(ops) != 2 || ops[1].Key != 2 {
+		t.Fatalf("error: log should contain the panicking op, but get %v %v", ops, err)
+	}
+}
+
+func TestMinimize(t *testing.T) {
+	// 模拟一个 bug：7 和 13 同时存在于树中时 panic
+	fails := func(ops []OpRecord[int, int]) bool {
+		rbt := NewRBTree[int, int]()
+		rbt.augment = func(n *node[int, int]) {
+			if n.key == 13 {
+				if _, m := rbt.search(7); m != nil {
+					panic("bug")
+				}
+			}
+		}
+		return Replay(rbt, ops, true) != nil
+	}
+	r := rand.New(rand.NewSource(1))
+	var ops []OpRecord[int, int]
+	for !fails(ops) {
+		op := OpRecord[int, int]{Op: OpInsert, Key: r.Intn(20), Value: len(ops)}
+		if r.Intn(2) == 0 {
+			op.Op = OpDelete
+		}
+		ops = append(ops, op)
+	}
+	ops = append(ops, make([]OpRecord[int, int], 50)...)
+
+	min := Minimize(ops, fails)
+	if len(min) != 2 || !fails(min) || min[0].Op != OpInsert || min[1].Op != OpInsert ||
+		min[0].Key+min[1].Key != 20 {
+		t.Fatalf("error: minimized ops should put 7 and 13, but get %v", min)
+	}
+
+	// 正确的红黑树不会失败，原样返回
+	if got := Minimize(ops, nil); len(got) != len(ops) {
+		t.Fatalf("error: passing ops should not be minimized, but get %v", len(got))
+	}
+}
+
+func TestReplayError(t *testing.T) {
+	rbt := NewRBTree[int, int]()
+	rbt.Put(1, 1)
+	// 根节点被错误地染成红色
+	rbt.root.color = red
+	err := Replay(rbt, []OpRecord[int, int]{{Op: OpDelete, Key: 5}}, true)
+	var re *ReplayError[int, int]
+	if !errors.As(err, &re) || re.Index != 0 || !errors.Is(err, ErrInvalidTree) {
+		t.Fatalf("error: replay should fail at op 0, but get %v", err)
+	}
+}
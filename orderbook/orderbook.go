@@ -0,0 +1,209 @@
+package orderbook
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+
+	"rbtree"
+)
+
+type Side byte
+
+const (
+	Bid Side = iota
+	Ask
+)
+
+func (s Side) String() string {
+	if s == Bid {
+		return "bid"
+	}
+	return "ask"
+}
+
+type Order struct {
+	ID    uint64
+	Side  Side
+	Price int64
+	Qty   int64
+}
+
+// Fill 是一次成交，Price 为被动方挂单的价格
+type Fill struct {
+	OrderID uint64
+	Price   int64
+	Qty     int64
+}
+
+// Level 是一个价位的汇总
+type Level struct {
+	Price  int64
+	Qty    int64
+	Orders int
+}
+
+var (
+	ErrDuplicateOrder = errors.New("orderbook: duplicate order id")
+	ErrInvalidQty     = errors.New("orderbook: quantity must be positive")
+)
+
+// 同一价位的订单按时间先后排队
+type level struct {
+	orders *list.List
+	qty    int64
+}
+
+// asks 和 bids 共同的操作，bids 使用 RBTree 的降序视图，Min 即为最高买价
+type levels interface {
+	Get(price int64) (*level, bool)
+	Put(price int64, l *level)
+	Remove(price int64) bool
+	Min() (int64, *level, bool)
+	Ascend(fn func(price int64, l *level) bool)
+}
+
+// Book 是限价订单簿，每个价位是一个先进先出的订单队列
+type Book struct {
+	mu     sync.Mutex
+	asks   *rbtree.RBTree[int64, *level]
+	bids   *rbtree.ReverseView[int64, *level]
+	orders map[uint64]*list.Element
+}
+
+func New() *Book {
+	return &Book{
+		asks:   rbtree.NewRBTree[int64, *level](),
+		bids:   rbtree.NewRBTree[int64, *level]().Reversed(),
+		orders: make(map[uint64]*list.Element),
+	}
+}
+
+func (b *Book) side(s Side) levels {
+	if s == Bid {
+		return b.bids
+	}
+	return b.asks
+}
+
+// Add 挂出限价单，不会与对手方撮合
+func (b *Book) Add(o Order) error {
+	if o.Qty <= 0 {
+		return ErrInvalidQty
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.orders[o.ID]; ok {
+		return ErrDuplicateOrder
+	}
+	side := b.side(o.Side)
+	l, ok := side.Get(o.Price)
+	if !ok {
+		l = &level{orders: list.New()}
+		side.Put(o.Price, l)
+	}
+	b.orders[o.ID] = l.orders.PushBack(&o)
+	l.qty += o.Qty
+	return nil
+}
+
+// Cancel 撤销订单，订单不存在（或已全部成交）时返回 false
+func (b *Book) Cancel(id uint64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.orders[id]
+	if !ok {
+		return false
+	}
+	o := e.Value.(*Order)
+	side := b.side(o.Side)
+	l, _ := side.Get(o.Price)
+	l.orders.Remove(e)
+	l.qty -= o.Qty
+	if l.orders.Len() == 0 {
+		side.Remove(o.Price)
+	}
+	delete(b.orders, id)
+	return true
+}
+
+// Match 以市价单的方式成交 qty：side 为 Bid 时从最低卖价开始吃单，为 Ask 时从最高买价开始，
+// 同一价位按挂单的先后顺序成交，返回成交记录和未成交的数量
+func (b *Book) Match(side Side, qty int64) ([]Fill, int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	opposite := b.side(Ask)
+	if side == Ask {
+		opposite = b.side(Bid)
+	}
+	var fills []Fill
+	for qty > 0 {
+		price, l, ok := opposite.Min()
+		if !ok {
+			break
+		}
+		for qty > 0 && l.orders.Len() > 0 {
+			e := l.orders.Front()
+			o := e.Value.(*Order)
+			n := o.Qty
+			if n > qty {
+				n = qty
+			}
+			fills = append(fills, Fill{o.ID, price, n})
+			o.Qty -= n
+			l.qty -= n
+			qty -= n
+			if o.Qty == 0 {
+				l.orders.Remove(e)
+				delete(b.orders, o.ID)
+			}
+		}
+		if l.orders.Len() == 0 {
+			opposite.Remove(price)
+		}
+	}
+	return fills, qty
+}
+
+// BestBid 返回最高买价
+func (b *Book) BestBid() (Level, bool) {
+	return b.best(Bid)
+}
+
+// BestAsk 返回最低卖价
+func (b *Book) BestAsk() (Level, bool) {
+	return b.best(Ask)
+}
+
+func (b *Book) best(s Side) (Level, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	price, l, ok := b.side(s).Min()
+	if !ok {
+		return Level{}, false
+	}
+	return Level{price, l.qty, l.orders.Len()}, true
+}
+
+// Depth 返回 side 最优的 n 个价位，买方按价格降序，卖方按价格升序；n <= 0 时返回所有价位
+func (b *Book) Depth(s Side, n int) []Level {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var res []Level
+	b.side(s).Ascend(func(price int64, l *level) bool {
+		res = append(res, Level{price, l.qty, l.orders.Len()})
+		return n <= 0 || len(res) < n
+	})
+	return res
+}
+
+// Order 返回订单当前的状态（Qty 为剩余数量）
+func (b *Book) Order(id uint64) (Order, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.orders[id]
+	if !ok {
+		return Order{}, false
+	}
+	return *e.Value.(*Order), true
+}
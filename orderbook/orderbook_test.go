@@ -0,0 +1,78 @@
+package orderbook
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBook(t *testing.T) {
+	b := New()
+	orders := []Order{
+		{1, Bid, 99, 10},
+		{2, Bid, 100, 5},
+		{3, Bid, 100, 7},
+		{4, Ask, 102, 4},
+		{5, Ask, 101, 6},
+		{6, Ask, 105, 20},
+	}
+	for _, o := range orders {
+		if err := b.Add(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Add(Order{1, Ask, 1, 1}); err != ErrDuplicateOrder {
+		t.Fatalf("error: duplicated id should return ErrDuplicateOrder, but get %v", err)
+	}
+	if err := b.Add(Order{7, Ask, 1, 0}); err != ErrInvalidQty {
+		t.Fatalf("error: zero qty should return ErrInvalidQty, but get %v", err)
+	}
+
+	if l, _ := b.BestBid(); l != (Level{100, 12, 2}) {
+		t.Fatalf("error: best bid should {100 12 2}, but get %v", l)
+	}
+	if l, _ := b.BestAsk(); l != (Level{101, 6, 1}) {
+		t.Fatalf("error: best ask should {101 6 1}, but get %v", l)
+	}
+	if d := fmt.Sprint(b.Depth(Bid, 0)); d != "[{100 12 2} {99 10 1}]" {
+		t.Fatalf("error: bid depth get %v", d)
+	}
+	if d := fmt.Sprint(b.Depth(Ask, 2)); d != "[{101 6 1} {102 4 1}]" {
+		t.Fatalf("error: ask depth get %v", d)
+	}
+
+	// 卖出 8：先成交 100 价位最早的订单 2，再成交订单 3 的一部分
+	fills, left := b.Match(Ask, 8)
+	if fmt.Sprint(fills) != "[{2 100 5} {3 100 3}]" || left != 0 {
+		t.Fatalf("error: match get %v %v", fills, left)
+	}
+	if o, _ := b.Order(3); o.Qty != 4 {
+		t.Fatalf("error: order 3 should left 4, but get %v", o.Qty)
+	}
+	if _, ok := b.Order(2); ok {
+		t.Fatal("error: filled order 2 should removed")
+	}
+
+	// 买入 15：吃掉 101、102 两个价位后在 105 成交剩余部分
+	fills, left = b.Match(Bid, 15)
+	if fmt.Sprint(fills) != "[{5 101 6} {4 102 4} {6 105 5}]" || left != 0 {
+		t.Fatalf("error: match get %v %v", fills, left)
+	}
+	if l, _ := b.BestAsk(); l != (Level{105, 15, 1}) {
+		t.Fatalf("error: best ask should {105 15 1}, but get %v", l)
+	}
+
+	if !b.Cancel(3) || b.Cancel(3) {
+		t.Fatal("error: cancel 3 should only succeed once")
+	}
+	if l, _ := b.BestBid(); l != (Level{99, 10, 1}) {
+		t.Fatalf("error: best bid should {99 10 1}, but get %v", l)
+	}
+
+	fills, left = b.Match(Ask, 30)
+	if fmt.Sprint(fills) != "[{1 99 10}]" || left != 20 {
+		t.Fatalf("error: match get %v %v", fills, left)
+	}
+	if _, ok := b.BestBid(); ok {
+		t.Fatal("error: bids should empty")
+	}
+}
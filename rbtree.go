@@ -23,10 +23,24 @@ type (
 	}
 
 	RBTree[K constraints.Ordered, V any] struct {
-		mu   sync.RWMutex
-		root *node[K, V]
-		size int
-		leaf *node[K, V]
+		mu       sync.RWMutex
+		root     *node[K, V]
+		size     int
+		leaf     *node[K, V]
+		watchers map[*watcher[K, V]]struct{}
+
+		keyCodec   Codec[K]
+		valueCodec Codec[V]
+		jsonFormat JSONFormat
+
+		// 维护子树附加信息（如区间树的最大右端点），节点的子树发生变化时调用
+		augment func(n *node[K, V])
+
+		// 为 nil 时不做任何统计
+		observer Observer
+		opStats  OpStats
+		// 为 nil 时不记录调整的过程
+		tracer Tracer[K]
 	}
 )
 
@@ -77,6 +91,15 @@ func NewRBTree[K constraints.Ordered, V any]() *RBTree[K, V] {
 	rbt := new(RBTree[K, V])
 	rbt.size = 0
 	rbt.root = nil
+	rbt.init()
+	return rbt
+}
+
+// 初始化叶子节点，使 new(RBTree) 或解码时创建的零值红黑树也可以使用
+func (rbt *RBTree[K, V]) init() {
+	if rbt.leaf != nil {
+		return
+	}
 	var key K
 	var value V
 	rbt.leaf = &node[K, V]{
@@ -87,7 +110,6 @@ func NewRBTree[K constraints.Ordered, V any]() *RBTree[K, V] {
 		nil,
 		black,
 	}
-	return rbt
 }
 
 func (rbt *RBTree[K, V]) Put(key K, value V) {
@@ -112,6 +134,68 @@ func (rbt *RBTree[K, V]) Remove(key K) bool {
 	return rbt.delete(key)
 }
 
+func (rbt *RBTree[K, V]) Len() int {
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	return rbt.size
+}
+
+// Ascend 按 key 升序遍历，fn 返回 false 时停止
+// 遍历期间持有读锁，fn 中不能修改红黑树
+func (rbt *RBTree[K, V]) Ascend(fn func(key K, value V) bool) {
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	rbt.walk(func(n *node[K, V]) bool {
+		return fn(n.key, n.value)
+	})
+}
+
+// AscendRange 按 key 升序遍历 [lo, hi) 范围内的节点，fn 返回 false 时停止
+func (rbt *RBTree[K, V]) AscendRange(lo, hi K, fn func(key K, value V) bool) {
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	for n := rbt.ceiling(lo); n != nil && n.key < hi; n = rbt.successor(n) {
+		if !fn(n.key, n.value) {
+			return
+		}
+	}
+}
+
+// Min 返回最小的 key
+func (rbt *RBTree[K, V]) Min() (key K, value V, ok bool) {
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	return rbt.entry(rbt.first())
+}
+
+// Max 返回最大的 key
+func (rbt *RBTree[K, V]) Max() (key K, value V, ok bool) {
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	return rbt.entry(rbt.last())
+}
+
+// Floor 返回小于等于 key 的最大 key
+func (rbt *RBTree[K, V]) Floor(key K) (K, V, bool) {
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	return rbt.entry(rbt.floor(key))
+}
+
+// Ceiling 返回大于等于 key 的最小 key
+func (rbt *RBTree[K, V]) Ceiling(key K) (K, V, bool) {
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	return rbt.entry(rbt.ceiling(key))
+}
+
+func (rbt *RBTree[K, V]) entry(n *node[K, V]) (key K, value V, ok bool) {
+	if n == nil {
+		return key, value, false
+	}
+	return n.key, n.value, true
+}
+
 func (rbt *RBTree[K, V]) createNode(key K, value V) *node[K, V] {
 	return &node[K, V]{
 		key,
@@ -125,7 +209,7 @@ func (rbt *RBTree[K, V]) createNode(key K, value V) *node[K, V] {
 
 func (rbt *RBTree[K, V]) search(key K) (prev, target *node[K, V]) {
 	curNode := rbt.root
-	for curNode != rbt.leaf {
+	for curNode != nil && curNode != rbt.leaf {
 		prev = curNode
 		if key < curNode.key {
 			curNode = curNode.left
@@ -140,36 +224,54 @@ func (rbt *RBTree[K, V]) search(key K) (prev, target *node[K, V]) {
 	return prev, nil
 }
 
-func (rbt *RBTree[K, V]) insert(key K, value V) {
+// 返回 key 所在的节点
+func (rbt *RBTree[K, V]) insert(key K, value V) *node[K, V] {
+	rbt.init()
+	rbt.opStats.Op = OpInsert
+	var n *node[K, V]
 	if rbt.root == nil {
-		node := rbt.createNode(key, value)
-		node.color = black
-		rbt.root = node
+		n = rbt.createNode(key, value)
+		n.color = black
+		rbt.root = n
+		rbt.augmentPath(n)
+		rbt.trace(TraceInsertCase, "1", n)
 
 	} else {
 		parent, target := rbt.search(key)
 		if target != nil {
+			old := target.value
 			target.value = value
-			return
+			rbt.augmentPath(target)
+			rbt.notify(EventUpdate, key, old, value)
+			return target
 		}
-		node := rbt.createNode(key, value)
-		node.parent = parent
-		if node.key < parent.key {
-			parent.left = node
+		n = rbt.createNode(key, value)
+		n.parent = parent
+		if n.key < parent.key {
+			parent.left = n
 		} else {
-			parent.right = node
+			parent.right = n
 		}
-		rbt.insertAdjust(node)
+		rbt.augmentPath(n)
+		rbt.insertAdjust(n)
 	}
 	rbt.size++
+	rbt.observe(OpInsert)
+	var zero V
+	rbt.notify(EventPut, key, zero, value)
+	return n
 }
 
 func (rbt *RBTree[K, V]) insertAdjust(n *node[K, V]) {
 	// case 1: 节点是root 或者 父节点是黑色
 	if n == rbt.root || n.parent.color == black {
+		rbt.trace(TraceInsertCase, "1", n)
 		return
 	}
 	for n != rbt.root && n.parent.color != black {
+		if rbt.observer != nil {
+			rbt.opStats.Steps++
+		}
 		p := n.getParent()
 		if p.color == red {
 			gp := n.getGrandfather()
@@ -183,9 +285,10 @@ func (rbt *RBTree[K, V]) insertAdjust(n *node[K, V]) {
 			// 父节点和叔父节点变色， 祖父节点变色
 			// 并以祖父节点为当前节点，继续向上调整红黑树
 			if u != nil && u.color == red {
-				p.changeColor()
-				u.changeColor()
-				gp.changeColor()
+				rbt.trace(TraceInsertCase, "2", n)
+				rbt.changeColor(p)
+				rbt.changeColor(u)
+				rbt.changeColor(gp)
 				n = gp
 				continue
 			}
@@ -200,11 +303,12 @@ func (rbt *RBTree[K, V]) insertAdjust(n *node[K, V]) {
 				//     /
 				//    n(r)
 				if n == p.left {
+					rbt.trace(TraceInsertCase, "3.1.1", n)
 					// 祖父节点右旋
 					rbt.rightRotate(gp)
 					// 父节点 和 祖父节点都变色，父节点 r->b, 祖父节点 b->r
-					p.changeColor()
-					gp.changeColor()
+					rbt.changeColor(p)
+					rbt.changeColor(gp)
 
 				} else {
 					// case 3.1.2 父节点是左节点，当前节点是右节点
@@ -214,6 +318,7 @@ func (rbt *RBTree[K, V]) insertAdjust(n *node[K, V]) {
 					//       \				  /
 					//    	 n(r)			 P(r)
 					// 父节点执行一次左旋后，变为case 3.1.1的情况
+					rbt.trace(TraceInsertCase, "3.1.2", n)
 					rbt.leftRotate(p)
 					// 进行下一次循环处理
 					n = p
@@ -228,11 +333,12 @@ func (rbt *RBTree[K, V]) insertAdjust(n *node[K, V]) {
 				// 	     \
 				//	      n(r)
 				if n == p.right {
+					rbt.trace(TraceInsertCase, "3.2.1", n)
 					// 祖父节点左旋
 					rbt.leftRotate(gp)
 					// 父节点 和 祖父节点都变色，父节点 r->b, 祖父节点 b->r
-					p.changeColor()
-					gp.changeColor()
+					rbt.changeColor(p)
+					rbt.changeColor(gp)
 				} else {
 					// case 3.2.2: 当前节点是父节点的左节点
 					// 		GP(b)                  GP(b)
@@ -241,6 +347,7 @@ func (rbt *RBTree[K, V]) insertAdjust(n *node[K, V]) {
 					//		   /						\
 					// 		 n(r)						P(r)
 					// 父节点右旋，当前节点设置为父节点，进行下一轮循环处理
+					rbt.trace(TraceInsertCase, "3.2.2", n)
 					rbt.rightRotate(p)
 					n = p
 					continue
@@ -248,10 +355,14 @@ func (rbt *RBTree[K, V]) insertAdjust(n *node[K, V]) {
 			}
 		}
 	}
-	rbt.root.color = black
+	rbt.setColor(rbt.root, black)
 }
 
 func (rbt *RBTree[K, V]) rightRotate(n *node[K, V]) {
+	if rbt.observer != nil {
+		rbt.opStats.RightRotations++
+	}
+	rbt.trace(TraceRotateRight, "", n)
 	left := n.left
 	n.left = left.right
 
@@ -273,13 +384,21 @@ func (rbt *RBTree[K, V]) rightRotate(n *node[K, V]) {
 
 	n.parent = left
 	left.right = n
+	if rbt.augment != nil {
+		rbt.augment(n)
+		rbt.augment(left)
+	}
 }
 
 func (rbt *RBTree[K, V]) leftRotate(n *node[K, V]) {
+	if rbt.observer != nil {
+		rbt.opStats.LeftRotations++
+	}
+	rbt.trace(TraceRotateLeft, "", n)
 	right := n.right
 	n.right = right.left
 
-	if right != rbt.leaf {
+	if right.left != rbt.leaf {
 		right.left.parent = n
 	}
 	right.parent = n.parent
@@ -295,42 +414,53 @@ func (rbt *RBTree[K, V]) leftRotate(n *node[K, V]) {
 
 	n.parent = right
 	right.left = n
+	if rbt.augment != nil {
+		rbt.augment(n)
+		rbt.augment(right)
+	}
 }
 
 func (rbt *RBTree[K, V]) delete(key K) bool {
-	parent, target := rbt.search(key)
+	_, target := rbt.search(key)
 	if target == nil {
 		return false
 	}
+	rbt.deleteNode(target)
+	return true
+}
 
+// 删除节点，其他节点的 key/value 和指针都不会改变
+func (rbt *RBTree[K, V]) deleteNode(target *node[K, V]) {
 	// 先删除，后进行调整
-
-	// case 1: 不存在子节点，直接删除
-	//               50(b)
-	//         /             \
-	//       20(b)          80(b)
-	//    /        \        /   \
-	//  13(r)     25(r)   leaf leaf
-	//  /   \     /   \
-	// leaf leaf leaf leaf
-	// 删除 80(b)
-	if target.left == rbt.leaf && target.right == rbt.leaf {
-		if parent == nil {
-			rbt.root = nil
-		} else if parent.left == target {
-			parent.left = rbt.leaf
-		} else {
-			parent.right = rbt.leaf
+	// 删除时只调整指针，不拷贝前驱节点的 key/value，保证节点身份不变
+	// removed: 实际从原位置移走的节点，x: 顶替 removed 位置的节点（可能是 leaf）
+	removed := target
+	removedColor := removed.color
+	var x *node[K, V]
+	rbt.opStats.Op = OpDelete
+	if rbt.tracer != nil {
+		switch {
+		case target.left == rbt.leaf && target.right == rbt.leaf:
+			rbt.trace(TraceRemoveCase, "1", target)
+		case target.left == rbt.leaf || target.right == rbt.leaf:
+			rbt.trace(TraceRemoveCase, "2", target)
+		default:
+			rbt.trace(TraceRemoveCase, "3", target)
 		}
-		if target.color == black {
-			rbt.leaf.parent = parent
-			target = rbt.leaf
-		}
-	} else if target.left == rbt.leaf {
-		// case 2: 删除节点只有一个子节点，替换为子节点，如果子节点仍有子节点
-		// 相当于转换为case2 或者 case3继续向下进行，总能替换为case1
-		// 但是根据红黑树的约束，单子树的节点绝对是黑色的，而其唯一子节点必然是红色的
-		// left = rbt.leaf则存在右子节点
+	}
+
+	if target.left == rbt.leaf {
+		// case 1: 不存在子节点，直接删除
+		//               50(b)
+		//         /             \
+		//       20(b)          80(b)
+		//    /        \        /   \
+		//  13(r)     25(r)   leaf leaf
+		//  /   \     /   \
+		// leaf leaf leaf leaf
+		// 删除 80(b)，用 leaf 顶替
+		// case 2: 删除节点只有一个子节点，替换为子节点
+		// 根据红黑树的约束，单子树的节点绝对是黑色的，而其唯一子节点必然是红色的
 		//            25(b)
 		//        /         \
 		//      20(b)      50(b)
@@ -339,21 +469,14 @@ func (rbt *RBTree[K, V]) delete(key K) bool {
 		//  /   \
 		// leaf leaf
 		// delete(20)
-		// 修改右节点颜色为黑色
-		target.right.changeColor()
+		x = target.right
 		rbt.exchange(target, target.right)
-		// 无需调整红黑树，直接返回
-		return true
 	} else if target.right == rbt.leaf {
-		// 修改左节点颜色为黑色
-		target.left.changeColor()
+		x = target.left
 		rbt.exchange(target, target.left)
-		// 无需调整红黑树，直接返回
-		return true
 	} else {
-		// case 3: 左右子节点都存在，查找后继(前驱)节点
-		// 后继节点（前驱节点）可能 右（左）子节点
-		s := rbt.precursor(target)
+		// case 3: 左右子节点都存在，查找前驱节点，用前驱节点替换被删除节点
+		// 前驱节点只可能存在左子节点
 		//                   50(b)
 		//         /                    \
 		//       20(b)                 75(r)
@@ -365,37 +488,30 @@ func (rbt *RBTree[K, V]) delete(key K) bool {
 		//                        leaf leaf
 		// delete(20)
 		// s = 13(r)
-		target.key = s.key
-		target.value = s.value
-
-		target = s
-
-		// 删除替换节点
-		if target.parent == nil {
-			rbt.root = nil
-		} else if target.parent.left == target {
-			target.parent.left = rbt.leaf
+		s := rbt.precursor(target)
+		removedColor = s.color
+		x = s.left
+		if s.parent == target {
+			x.parent = s
 		} else {
-			target.parent.right = rbt.leaf
-		}
-		// 如果替换节点是黑色的，那么父节点一定有两个子节点
-		rbt.leaf.parent = target.parent
-
-		// 替换节点只可能存在一个子节点
-		if target.left != rbt.leaf {
-			rbt.exchange(target, target.left)
-		} else if target.right != rbt.leaf {
-			rbt.exchange(target, target.right)
-		}
-		// 如果替换节点的颜色为黑色，则用叶子节点代替
-		if target.color == black {
-			target = rbt.leaf
+			rbt.exchange(s, s.left)
+			s.left = target.left
+			s.left.parent = s
 		}
+		rbt.exchange(target, s)
+		s.right = target.right
+		s.right.parent = s
+		s.color = target.color
+	}
+
+	// 如果被移走的节点颜色是黑色则需要调整
+	// 从结构发生变化的最低位置向上更新附加信息，之后的旋转只影响局部
+	rbt.augmentPath(x.parent)
+	if removedColor == black {
+		rbt.deleteAdjust(x)
 	}
-	//fmt.Println("target", target)
-	// 如果被删除的替换节点颜色是黑色则需要调整
-	if target.color == black {
-		rbt.deleteAdjust(target)
+	if rbt.root == rbt.leaf {
+		rbt.root = nil
 	}
 
 	target.parent = nil
@@ -404,7 +520,9 @@ func (rbt *RBTree[K, V]) delete(key K) bool {
 	rbt.leaf.parent = nil
 
 	rbt.size--
-	return true
+	rbt.observe(OpDelete)
+	var zero V
+	rbt.notify(EventDelete, target.key, target.value, zero)
 }
 
 func (rbt *RBTree[K, V]) exchange(a, b *node[K, V]) {
@@ -423,6 +541,9 @@ func (rbt *RBTree[K, V]) exchange(a, b *node[K, V]) {
 func (rbt *RBTree[K, V]) deleteAdjust(n *node[K, V]) {
 
 	for n != rbt.root && n.color == black {
+		if rbt.observer != nil {
+			rbt.opStats.Steps++
+		}
 		s := n.getSibling()
 		//fmt.Println("sibling", s)
 		p := n.getParent()
@@ -439,10 +560,11 @@ func (rbt *RBTree[K, V]) deleteAdjust(n *node[K, V]) {
 			//           /   \    /   \             /    \    /    \                /    \
 			//         leaf leaf leaf leaf         leaf leaf leaf leaf            leaf  leaf
 			if s.color == red {
+				rbt.trace(TraceFixCase, "2", p)
 				// s设为黑色
-				s.changeColor()
+				rbt.changeColor(s)
 				// p设为红色
-				p.color = red
+				rbt.setColor(p, red)
 				// p左旋
 				rbt.leftRotate(p)
 				// 更新兄弟节点
@@ -461,8 +583,9 @@ func (rbt *RBTree[K, V]) deleteAdjust(n *node[K, V]) {
 				//           /   \    /   \                              /   \							  /   \
 				//         leaf leaf leaf leaf                         leaf  leaf                       leaf  leaf
 				if s.left.color == black && s.right.color == black {
+					rbt.trace(TraceFixCase, "1.1", p)
 					// 将兄弟节点设为红色
-					s.changeColor()
+					rbt.changeColor(s)
 					// 把父节点作为要调整的节点，继续向上调整
 					n = n.parent
 					continue
@@ -477,12 +600,13 @@ func (rbt *RBTree[K, V]) deleteAdjust(n *node[K, V]) {
 				//                /   \                         /   \
 				//              leaf leaf                     leaf leaf
 				if s.right.color == red {
+					rbt.trace(TraceFixCase, "1.2", p)
 					// s的颜色设为p的颜色，p黑色红色都有可能
-					s.color = p.color
+					rbt.setColor(s, p.color)
 					// p设为黑色
-					p.color = black
+					rbt.setColor(p, black)
 					// sr设为黑色
-					s.right.color = black
+					rbt.setColor(s.right, black)
 					// p左旋
 					rbt.leftRotate(p)
 					// 调整结束
@@ -499,10 +623,11 @@ func (rbt *RBTree[K, V]) deleteAdjust(n *node[K, V]) {
 				//           /   \                         /   \                            /   \
 				//         leaf leaf                     leaf leaf                        leaf leaf
 				if s.right.color == black && s.left.color == red {
+					rbt.trace(TraceFixCase, "1.3", p)
 					// s设为红色
-					s.changeColor()
+					rbt.changeColor(s)
 					// sl设为黑色
-					s.left.changeColor()
+					rbt.changeColor(s.left)
 					// s右旋
 					rbt.rightRotate(s)
 					// continue
@@ -512,10 +637,11 @@ func (rbt *RBTree[K, V]) deleteAdjust(n *node[K, V]) {
 
 		} else {
 			if s.color == red {
+				rbt.trace(TraceFixCase, "2", p)
 				// s设为黑色
-				s.changeColor()
+				rbt.changeColor(s)
 				// p设为红色
-				p.color = red
+				rbt.setColor(p, red)
 				// p右旋
 				rbt.rightRotate(p)
 				// 更新兄弟节点
@@ -524,20 +650,22 @@ func (rbt *RBTree[K, V]) deleteAdjust(n *node[K, V]) {
 
 			// 兄弟节点的子节点都是黑色
 			if s.left.color == black && s.right.color == black {
+				rbt.trace(TraceFixCase, "1.1", p)
 				// 将兄弟节点设为红色
-				s.changeColor()
+				rbt.changeColor(s)
 				// 把父节点作为要调整的节点，继续向上调整
 				n = n.parent
 				continue
 			}
 			// 兄弟节点左子节点红色
 			if s.left.color == red {
+				rbt.trace(TraceFixCase, "1.2", p)
 				// s的颜色设为p的颜色，p黑色红色都有可能
-				s.color = p.color
+				rbt.setColor(s, p.color)
 				// p设为黑色
-				p.color = black
+				rbt.setColor(p, black)
 				// sl设为黑色
-				s.left.color = black
+				rbt.setColor(s.left, black)
 				// p右旋
 				rbt.rightRotate(p)
 				// 调整结束
@@ -545,10 +673,11 @@ func (rbt *RBTree[K, V]) deleteAdjust(n *node[K, V]) {
 			}
 			// 兄弟节点的左子节点黑色，右子节点红色
 			if s.left.color == black && s.right.color == red {
+				rbt.trace(TraceFixCase, "1.3", p)
 				// s设为红色
-				s.changeColor()
+				rbt.changeColor(s)
 				// s2设为黑色
-				s.right.changeColor()
+				rbt.changeColor(s.right)
 				// s右旋
 				rbt.leftRotate(s)
 				// continue
@@ -556,7 +685,7 @@ func (rbt *RBTree[K, V]) deleteAdjust(n *node[K, V]) {
 			}
 		}
 	}
-	n.color = black
+	rbt.setColor(n, black)
 }
 
 func (rbt *RBTree[K, V]) precursor(n *node[K, V]) *node[K, V] {
@@ -571,7 +700,7 @@ func (rbt *RBTree[K, V]) precursor(n *node[K, V]) *node[K, V] {
 	}
 	// 没有左子树，前驱节点的右节点为该节点的父节点或祖父节点
 	p := n.parent
-	for p != rbt.leaf && n == p.left {
+	for p != nil && n == p.left {
 		n = p
 		p = p.parent
 	}
@@ -588,10 +717,121 @@ func (rbt *RBTree[K, V]) successor(n *node[K, V]) *node[K, V] {
 		return cur
 	}
 	p := n.parent
-	for p != rbt.leaf && n == p.right {
+	for p != nil && n == p.right {
 		n = p
 		p = p.parent
 	}
 
 	return p
 }
+
+// 最小节点
+func (rbt *RBTree[K, V]) first() *node[K, V] {
+	if rbt.root == nil {
+		return nil
+	}
+	cur := rbt.root
+	for cur.left != rbt.leaf {
+		cur = cur.left
+	}
+	return cur
+}
+
+// 最大节点
+func (rbt *RBTree[K, V]) last() *node[K, V] {
+	if rbt.root == nil {
+		return nil
+	}
+	cur := rbt.root
+	for cur.right != rbt.leaf {
+		cur = cur.right
+	}
+	return cur
+}
+
+// 小于等于 key 的最大节点
+func (rbt *RBTree[K, V]) floor(key K) (res *node[K, V]) {
+	cur := rbt.root
+	for cur != nil && cur != rbt.leaf {
+		if key < cur.key {
+			cur = cur.left
+		} else if key > cur.key {
+			res = cur
+			cur = cur.right
+		} else {
+			return cur
+		}
+	}
+	return res
+}
+
+// 大于等于 key 的最小节点
+func (rbt *RBTree[K, V]) ceiling(key K) (res *node[K, V]) {
+	cur := rbt.root
+	for cur != nil && cur != rbt.leaf {
+		if key > cur.key {
+			cur = cur.right
+		} else if key < cur.key {
+			res = cur
+			cur = cur.left
+		} else {
+			return cur
+		}
+	}
+	return res
+}
+
+// 中序遍历，fn 返回 false 时停止
+func (rbt *RBTree[K, V]) walk(fn func(n *node[K, V]) bool) {
+	for n := rbt.first(); n != nil; n = rbt.successor(n) {
+		if !fn(n) {
+			return
+		}
+	}
+}
+
+// 由升序排列的 keys、values 在 O(n) 时间内构造一棵平衡的红黑树，替换原有的节点
+// 取中间元素作为根递归构造，所有叶子的深度只相差 1，
+// 最底层不满时把最底层的节点染成红色，其余节点为黑色
+func (rbt *RBTree[K, V]) build(keys []K, values []V) {
+	rbt.init()
+	n := len(keys)
+	// 完全填满的层数
+	full := 0
+	for (1<<(full+1))-1 <= n {
+		full++
+	}
+	var build func(lo, hi, depth int, parent *node[K, V]) *node[K, V]
+	build = func(lo, hi, depth int, parent *node[K, V]) *node[K, V] {
+		if lo > hi {
+			return rbt.leaf
+		}
+		mid := lo + (hi-lo)/2
+		n := rbt.createNode(keys[mid], values[mid])
+		n.parent = parent
+		if depth < full {
+			n.color = black
+		}
+		n.left = build(lo, mid-1, depth+1, n)
+		n.right = build(mid+1, hi, depth+1, n)
+		if rbt.augment != nil {
+			rbt.augment(n)
+		}
+		return n
+	}
+	rbt.root = nil
+	if n > 0 {
+		rbt.root = build(0, n-1, 0, nil)
+	}
+	rbt.size = n
+}
+
+// 更新从 n 到根节点路径上所有节点的附加信息
+func (rbt *RBTree[K, V]) augmentPath(n *node[K, V]) {
+	if rbt.augment == nil {
+		return
+	}
+	for ; n != nil && n != rbt.leaf; n = n.parent {
+		rbt.augment(n)
+	}
+}
@@ -2,32 +2,19 @@ package rbtree
 
 import (
 	"fmt"
-	"strconv"
+	"math/rand"
 	"strings"
 	"testing"
 	"unsafe"
-)
 
-func (c color) String() string {
-	if c == 0 {
-		return "r"
-	}
-	return "b"
-}
+	"golang.org/x/exp/constraints"
+)
 
 func (n *node[K, V]) String() string {
 	return fmt.Sprintf("key=%v, value=%v, parent=%v, left=%v, right=%v, color=%v",
 		n.key, n.value, unsafe.Pointer(n.parent), unsafe.Pointer(n.left), unsafe.Pointer(n.right), n.color)
 }
 
-type printTree struct {
-	str   string
-	left  *printTree
-	right *printTree
-	x     int
-	level int
-}
-
 func max(a, b int) int {
 	if a > b {
 		return a
@@ -42,145 +29,9 @@ func min(a, b int) int {
 	return b
 }
 
-// 实现红黑树的简单可视化
-// 如：
-//            25(b)
-//        /         \
-//      20(b)      50(b)
-//    /      \     /   \
-//  13(r)   leaf leaf leaf
-//  /   \
-// leaf leaf
-func String(rbt *RBTree[int, int]) string {
-	levelX := make([]int, 1000)
-	l := 0
-	var initPrintTree func(*node[int, int], int) *printTree
-	initPrintTree = func(n *node[int, int], level int) *printTree {
-		if n == nil {
-			l = max(level-1, l)
-			return nil
-		}
-
-		left := initPrintTree(n.left, level+1)
-		right := initPrintTree(n.right, level+1)
-
-		p := new(printTree)
-
-		if n == rbt.leaf {
-			p.str = "leaf"
-		} else {
-			p.str = strconv.Itoa(n.key) + "(" + n.color.String() + ")"
-		}
-		p.left = left
-		p.right = right
-		p.level = level
-		return p
-	}
-
-	var generateCoor func(*printTree)
-
-	pt := initPrintTree(rbt.root, 0)
-	generateCoor = func(p *printTree) {
-		if p == nil {
-			return
-		}
-		if levelX[p.level] < 0 {
-			for i := 0; i < p.level; i++ {
-				levelX[p.level] = -levelX[p.level]
-			}
-			generateCoor(pt)
-			return
-		}
-		generateCoor(p.left)
-		generateCoor(p.right)
-
-		var px int
-		if p.left != nil && p.right != nil {
-			px = max((p.right.x+p.left.x)/2, 1)
-		} else if p.left != nil {
-			px = p.left.x + len(p.left.str)/2 + 1
-		} else if p.right != nil {
-			px = max(p.right.x+len(p.right.str)/2+1, levelX[p.level]+len(p.str)/2)
-		} else {
-			px = levelX[p.level]
-		}
-
-		if p.level > 0 && px+len(p.str)/2 <= levelX[p.level] {
-
-			if p.left != nil {
-				levelX[p.level+1] = levelX[p.level] - len(p.left.str)/2 + 1
-			} else {
-				levelX[p.level+1] = levelX[p.level] - len(p.str)/2 + 1
-			}
-
-			generateCoor(p)
-			if p.left != nil && p.right != nil {
-				px = max((p.right.x+p.left.x)/2, 1)
-			} else if p.left != nil {
-				px = p.left.x + len(p.left.str)/2
-			} else if p.right != nil {
-				px = p.right.x + len(p.right.str)/2
-			} else {
-				px = levelX[p.level] + 1
-			}
-		}
-		p.x = px
-		if p.right != nil {
-			levelX[p.level] = max(p.right.x+len(p.right.str)+1, px+len(p.str)+1)
-		} else {
-			if p.left != nil {
-				levelX[p.level] = px + max(len(p.left.str)/2, len(p.str)) + 1
-			} else {
-				levelX[p.level] = px + len(p.str) + 1
-			}
-		}
-	}
-
-	generateCoor(pt)
-
-	que := make([]*printTree, 0, rbt.size)
-	sb := strings.Builder{}
-	que = append(que, pt)
-	for len(que) != 0 {
-		cnt := len(que)
-		curX := 0
-		curS := 0
-		tmpB := strings.Builder{}
-		for i := 0; i < cnt; i++ {
-			n := que[0]
-			que = que[1:]
-			if n.x <= curX {
-				n.x += (curX - n.x) + 1
-			}
-			sb.WriteString(strings.Repeat(" ", n.x-curX))
-			sb.WriteString(n.str)
-			curX = n.x + len(n.str)
-
-			if n.left != nil {
-				tmpB.WriteString(strings.Repeat(" ", n.left.x-curS+len(n.left.str)/2))
-				if n.left.x != n.x {
-					tmpB.WriteString("/")
-				} else {
-					tmpB.WriteString("|")
-				}
-				curS += n.left.x - curS + len(n.left.str)/2 + 1
-				que = append(que, n.left)
-			}
-			if n.right != nil {
-				tmpB.WriteString(strings.Repeat(" ", n.right.x-curS+len(n.right.str)/2-1))
-				if n.right.x != n.x {
-					tmpB.WriteString("\\")
-				} else {
-					tmpB.WriteString("|")
-				}
-				curS += n.right.x - curS + len(n.right.str)/2
-
-				que = append(que, n.right)
-			}
-		}
-		sb.WriteString("\n")
-		sb.WriteString(tmpB.String() + "\n")
-	}
+func String[K constraints.Ordered, V any](rbt *RBTree[K, V]) string {
+	var sb strings.Builder
+	rbt.Render(&sb, &RenderOptions[K, V]{Leaves: true})
 	return sb.String()
 }
 
@@ -381,3 +232,109 @@ func TestRBTreePutGetAndRemove(t *testing.T) {
 	}
 	fmt.Println(String(rbt))
 }
+
+// 检查红黑树的性质，返回节点数
+func checkRBTree[K constraints.Ordered, V any](t *testing.T, rbt *RBTree[K, V]) int {
+	t.Helper()
+	if err := rbt.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	return rbt.Len()
+}
+
+func TestRBTreeRandomPutAndRemove(t *testing.T) {
+	for seed := int64(0); seed < 100; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		rbt := NewRBTree[int, int]()
+		m := make(map[int]int)
+		for i := 0; i < 500; i++ {
+			k := r.Intn(100)
+			if r.Intn(2) == 0 {
+				rbt.Put(k, i)
+				m[k] = i
+			} else {
+				_, ok := m[k]
+				if rbt.Remove(k) != ok {
+					t.Fatalf("error: remove %v should return %v", k, ok)
+				}
+				delete(m, k)
+			}
+			if checkRBTree(t, rbt) != len(m) {
+				t.Fatalf("error: rbtree size should %v", len(m))
+			}
+		}
+		for k, v := range m {
+			if value, ok := rbt.Get(k); !ok || value != v {
+				t.Fatalf("error: get %v should %v, but get %v", k, v, value)
+			}
+		}
+	}
+}
+
+func TestRBTreeRemoveAll(t *testing.T) {
+	rbt := NewRBTree[int, int]()
+	if _, ok := rbt.Get(1); ok {
+		t.Fatal("error: empty rbtree should not contain 1")
+	}
+	for i := 0; i < 10; i++ {
+		rbt.Put(i, i)
+	}
+	for i := 0; i < 10; i++ {
+		rbt.Remove(i)
+	}
+	if rbt.root != nil || rbt.size != 0 {
+		t.Fatal("error: rbtree should empty")
+	}
+	rbt.Put(1, 1)
+	checkRBTree(t, rbt)
+}
+
+func TestRBTreeAscend(t *testing.T) {
+	rbt := NewRBTree[int, int]()
+	for _, k := range []int{5, 3, 8, 1, 4, 7, 9} {
+		rbt.Put(k, k*10)
+	}
+	var keys []int
+	rbt.Ascend(func(k, v int) bool {
+		if v != k*10 {
+			t.Fatalf("error: value of %v should %v, but get %v", k, k*10, v)
+		}
+		keys = append(keys, k)
+		return k < 7
+	})
+	if fmt.Sprint(keys) != "[1 3 4 5 7]" {
+		t.Fatalf("error: ascend should [1 3 4 5 7], but get %v", keys)
+	}
+	if rbt.Len() != 7 {
+		t.Fatalf("error: rbtree len should 7, but get %v", rbt.Len())
+	}
+}
+
+func TestRBTreeFloorAndCeiling(t *testing.T) {
+	rbt := NewRBTree[int, int]()
+	if _, _, ok := rbt.Min(); ok {
+		t.Fatal("error: empty rbtree should not have min")
+	}
+	if _, _, ok := rbt.Floor(1); ok {
+		t.Fatal("error: empty rbtree should not have floor")
+	}
+	for i := 0; i < 100; i += 10 {
+		rbt.Put(i, i)
+	}
+	for i := -5; i < 105; i++ {
+		k, _, ok := rbt.Floor(i)
+		if want := i / 10 * 10; ok != (i >= 0) || ok && k != min(want, 90) {
+			t.Fatalf("error: floor %v get %v %v", i, k, ok)
+		}
+		k, _, ok = rbt.Ceiling(i)
+		if want := (i + 9) / 10 * 10; ok != (i <= 90) || ok && k != max(want, 0) {
+			t.Fatalf("error: ceiling %v get %v %v", i, k, ok)
+		}
+	}
+	if k, _, _ := rbt.Min(); k != 0 {
+		t.Fatalf("error: min should 0, but get %v", k)
+	}
+	if k, _, _ := rbt.Max(); k != 90 {
+		t.Fatalf("error: max should 90, but get %v", k)
+	}
+}
@@ -0,0 +1,207 @@
+package rbtree
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+func (c color) String() string {
+	if c == red {
+		return "r"
+	}
+	return "b"
+}
+
+type RenderOptions[K any, V any] struct {
+	// 节点的显示内容，nil 时只显示 key
+	Format func(key K, value V) string
+	// 使用 ANSI 颜色显示红色节点，否则在节点后面加上 (r)/(b)
+	Color bool
+	// 是否显示叶子节点
+	Leaves bool
+}
+
+const (
+	ansiRed   = "\x1b[31m"
+	ansiReset = "\x1b[0m"
+)
+
+type renderNode struct {
+	label       string
+	red         bool
+	width       int
+	start       int
+	left, right *renderNode
+}
+
+func (r *renderNode) center() int {
+	return r.start + r.width/2
+}
+
+func (rbt *RBTree[K, V]) format(opts *RenderOptions[K, V], n *node[K, V]) string {
+	if opts != nil && opts.Format != nil {
+		return opts.Format(n.key, n.value)
+	}
+	return fmt.Sprint(n.key)
+}
+
+// Render 以 ASCII 图的形式输出红黑树，如：
+//
+//	       25(b)
+//	    /        \
+//	  20(b)     50(b)
+//	  /
+//	13(r)
+//
+// 节点按中序排列在不同的列上，同一层的节点不会重叠
+func (rbt *RBTree[K, V]) Render(w io.Writer, opts *RenderOptions[K, V]) error {
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	if rbt.root == nil {
+		_, err := io.WriteString(w, "empty\n")
+		return err
+	}
+	colored := opts != nil && opts.Color
+	leaves := opts != nil && opts.Leaves
+
+	col := 0
+	var build func(n *node[K, V]) *renderNode
+	build = func(n *node[K, V]) *renderNode {
+		if n == rbt.leaf && !leaves {
+			return nil
+		}
+		r := new(renderNode)
+		if n != rbt.leaf {
+			r.left = build(n.left)
+		}
+		r.red = n.color == red
+		if n == rbt.leaf {
+			r.label = "leaf"
+		} else if colored {
+			r.label = rbt.format(opts, n)
+		} else {
+			r.label = rbt.format(opts, n) + "(" + n.color.String() + ")"
+		}
+		r.width = utf8.RuneCountInString(r.label)
+		r.start = col
+		col += r.width + 1
+		if n != rbt.leaf {
+			r.right = build(n.right)
+		}
+		return r
+	}
+	root := build(rbt.root)
+
+	bw := bufio.NewWriter(w)
+	for level := []*renderNode{root}; len(level) > 0; {
+		var labels, links strings.Builder
+		labelCol, linkCol := 0, 0
+		var next []*renderNode
+		for _, r := range level {
+			labels.WriteString(strings.Repeat(" ", r.start-labelCol))
+			if colored && r.red {
+				labels.WriteString(ansiRed + r.label + ansiReset)
+			} else {
+				labels.WriteString(r.label)
+			}
+			labelCol = r.start + r.width
+			if r.left != nil {
+				c := (r.left.center() + r.center()) / 2
+				links.WriteString(strings.Repeat(" ", c-linkCol) + "/")
+				linkCol = c + 1
+				next = append(next, r.left)
+			}
+			if r.right != nil {
+				c := (r.right.center() + r.center() + 1) / 2
+				links.WriteString(strings.Repeat(" ", c-linkCol) + "\\")
+				linkCol = c + 1
+				next = append(next, r.right)
+			}
+		}
+		bw.WriteString(labels.String() + "\n")
+		if len(next) > 0 {
+			bw.WriteString(links.String() + "\n")
+		}
+		level = next
+	}
+	return bw.Flush()
+}
+
+// WriteDOT 以 Graphviz DOT 格式输出红黑树，format 为 nil 时只显示 key
+// 只有一个子节点时用不可见的节点占位，保证左右子节点的位置正确
+func (rbt *RBTree[K, V]) WriteDOT(w io.Writer, format func(key K, value V) string) error {
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	opts := &RenderOptions[K, V]{Format: format}
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph rbtree {\n")
+	bw.WriteString("\tnode [shape=circle, style=filled, fontcolor=white];\n")
+	id := 0
+	var write func(n *node[K, V]) int
+	write = func(n *node[K, V]) int {
+		cur := id
+		id++
+		if n == rbt.leaf {
+			fmt.Fprintf(bw, "\tn%d [shape=point, style=invis];\n", cur)
+			return cur
+		}
+		fill := "black"
+		if n.color == red {
+			fill = "red"
+		}
+		fmt.Fprintf(bw, "\tn%d [label=%q, fillcolor=%s];\n", cur, rbt.format(opts, n), fill)
+		if n.left == rbt.leaf && n.right == rbt.leaf {
+			return cur
+		}
+		for _, child := range []*node[K, V]{n.left, n.right} {
+			c := write(child)
+			if child == rbt.leaf {
+				fmt.Fprintf(bw, "\tn%d -> n%d [style=invis];\n", cur, c)
+			} else {
+				fmt.Fprintf(bw, "\tn%d -> n%d;\n", cur, c)
+			}
+		}
+		return cur
+	}
+	if rbt.root != nil {
+		write(rbt.root)
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+// WriteMermaid 以 Mermaid flowchart 格式输出红黑树，format 为 nil 时只显示 key
+func (rbt *RBTree[K, V]) WriteMermaid(w io.Writer, format func(key K, value V) string) error {
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	opts := &RenderOptions[K, V]{Format: format}
+	bw := bufio.NewWriter(w)
+	bw.WriteString("graph TD\n")
+	bw.WriteString("\tclassDef red fill:#d62728,color:#fff\n")
+	bw.WriteString("\tclassDef black fill:#000,color:#fff\n")
+	id := 0
+	var write func(n *node[K, V]) int
+	write = func(n *node[K, V]) int {
+		cur := id
+		id++
+		label := strings.ReplaceAll(rbt.format(opts, n), `"`, "#quot;")
+		class := "black"
+		if n.color == red {
+			class = "red"
+		}
+		fmt.Fprintf(bw, "\tn%d((\"%s\")):::%s\n", cur, label, class)
+		for _, child := range []*node[K, V]{n.left, n.right} {
+			if child != rbt.leaf {
+				fmt.Fprintf(bw, "\tn%d --> n%d\n", cur, write(child))
+			}
+		}
+		return cur
+	}
+	if rbt.root != nil {
+		write(rbt.root)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,88 @@
+package rbtree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRBTreeRender(t *testing.T) {
+	rbt := NewRBTree[int, int]()
+	var sb strings.Builder
+	rbt.Render(&sb, nil)
+	if sb.String() != "empty\n" {
+		t.Fatalf("error: empty rbtree render get %q", sb.String())
+	}
+
+	for _, k := range []int{25, 20, 50, 13} {
+		rbt.Put(k, 0)
+	}
+	sb.Reset()
+	rbt.Render(&sb, nil)
+	want := "" +
+		"            25(b)\n" +
+		"           /     \\\n" +
+		"      20(b)       50(b)\n" +
+		"     /\n" +
+		"13(r)\n"
+	if sb.String() != want {
+		t.Fatalf("error: render should\n%v\nbut get\n%v", want, sb.String())
+	}
+}
+
+func TestRBTreeRenderFormat(t *testing.T) {
+	rbt := NewRBTree[string, int]()
+	rbt.Put("b", 2)
+	rbt.Put("a", 1)
+	var sb strings.Builder
+	rbt.Render(&sb, &RenderOptions[string, int]{
+		Format: func(k string, v int) string { return k + "=" + strings.Repeat("*", v) },
+		Color:  true,
+		Leaves: true,
+	})
+	lines := strings.Split(sb.String(), "\n")
+	if !strings.Contains(lines[0], "b=**") || strings.Contains(lines[0], ansiRed) {
+		t.Fatalf("error: black root should not colored, get %q", lines[0])
+	}
+	if !strings.Contains(lines[2], ansiRed+"a=*"+ansiReset) || !strings.Contains(lines[2], "leaf") {
+		t.Fatalf("error: red node should colored, get %q", lines[2])
+	}
+}
+
+func TestRBTreeWriteDOT(t *testing.T) {
+	rbt := NewRBTree[string, int]()
+	rbt.Put("b", 2)
+	rbt.Put(`a"`, 1)
+	var sb strings.Builder
+	rbt.WriteDOT(&sb, nil)
+	want := "digraph rbtree {\n" +
+		"\tnode [shape=circle, style=filled, fontcolor=white];\n" +
+		"\tn0 [label=\"b\", fillcolor=black];\n" +
+		"\tn1 [label=\"a\\\"\", fillcolor=red];\n" +
+		"\tn0 -> n1;\n" +
+		"\tn2 [shape=point, style=invis];\n" +
+		"\tn0 -> n2 [style=invis];\n" +
+		"}\n"
+	if sb.String() != want {
+		t.Fatalf("error: dot should\n%v\nbut get\n%v", want, sb.String())
+	}
+}
+
+func TestRBTreeWriteMermaid(t *testing.T) {
+	rbt := NewRBTree[int, string]()
+	rbt.Put(2, "b")
+	rbt.Put(1, "a")
+	rbt.Put(3, "c")
+	var sb strings.Builder
+	rbt.WriteMermaid(&sb, func(k int, v string) string { return v })
+	want := "graph TD\n" +
+		"\tclassDef red fill:#d62728,color:#fff\n" +
+		"\tclassDef black fill:#000,color:#fff\n" +
+		"\tn0((\"b\")):::black\n" +
+		"\tn1((\"a\")):::red\n" +
+		"\tn0 --> n1\n" +
+		"\tn2((\"c\")):::red\n" +
+		"\tn0 --> n2\n"
+	if sb.String() != want {
+		t.Fatalf("error: mermaid should\n%v\nbut get\n%v", want, sb.String())
+	}
+}
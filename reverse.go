@@ -0,0 +1,80 @@
+package rbtree
+
+import "golang.org/x/exp/constraints"
+
+// ReverseView 是按 key 降序看待红黑树的视图，与原来的红黑树共享数据
+// 遍历、Min/Max、Floor/Ceiling 和范围查询都是镜像的：
+// Min 返回最大的 key，Floor(k) 返回大于等于 k 的最小 key，依此类推
+type ReverseView[K constraints.Ordered, V any] struct {
+	tree *RBTree[K, V]
+}
+
+func (rbt *RBTree[K, V]) Reversed() *ReverseView[K, V] {
+	return &ReverseView[K, V]{tree: rbt}
+}
+
+// Reversed 返回原来的红黑树
+func (r *ReverseView[K, V]) Reversed() *RBTree[K, V] {
+	return r.tree
+}
+
+func (r *ReverseView[K, V]) Get(key K) (V, bool) {
+	return r.tree.Get(key)
+}
+
+func (r *ReverseView[K, V]) Put(key K, value V) {
+	r.tree.Put(key, value)
+}
+
+func (r *ReverseView[K, V]) Remove(key K) bool {
+	return r.tree.Remove(key)
+}
+
+func (r *ReverseView[K, V]) Len() int {
+	return r.tree.Len()
+}
+
+// Ascend 按 key 降序遍历，fn 返回 false 时停止
+func (r *ReverseView[K, V]) Ascend(fn func(key K, value V) bool) {
+	rbt := r.tree
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	for n := rbt.last(); n != nil; n = rbt.precursor(n) {
+		if !fn(n.key, n.value) {
+			return
+		}
+	}
+}
+
+// AscendRange 按 key 降序遍历 (hi, lo] 范围内的节点，即从 lo 开始向下直到 hi（不包括），
+// 与 RBTree.AscendRange 的 [lo, hi) 镜像
+func (r *ReverseView[K, V]) AscendRange(lo, hi K, fn func(key K, value V) bool) {
+	rbt := r.tree
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	for n := rbt.floor(lo); n != nil && n.key > hi; n = rbt.precursor(n) {
+		if !fn(n.key, n.value) {
+			return
+		}
+	}
+}
+
+// Min 返回降序中的第一个，即最大的 key
+func (r *ReverseView[K, V]) Min() (K, V, bool) {
+	return r.tree.Max()
+}
+
+// Max 返回降序中的最后一个，即最小的 key
+func (r *ReverseView[K, V]) Max() (K, V, bool) {
+	return r.tree.Min()
+}
+
+// Floor 返回降序中不超过 key 的最后一个，即大于等于 key 的最小 key
+func (r *ReverseView[K, V]) Floor(key K) (K, V, bool) {
+	return r.tree.Ceiling(key)
+}
+
+// Ceiling 返回降序中不先于 key 的第一个，即小于等于 key 的最大 key
+func (r *ReverseView[K, V]) Ceiling(key K) (K, V, bool) {
+	return r.tree.Floor(key)
+}
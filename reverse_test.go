@@ -0,0 +1,54 @@
+package rbtree
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRBTreeReversed(t *testing.T) {
+	rbt := NewRBTree[int, int]()
+	for i := 0; i < 10; i++ {
+		rbt.Put(i*10, i)
+	}
+	r := rbt.Reversed()
+	var keys []int
+	r.Ascend(func(k, _ int) bool {
+		keys = append(keys, k)
+		return k > 50
+	})
+	if fmt.Sprint(keys) != "[90 80 70 60 50]" {
+		t.Fatalf("error: reversed ascend should [90 80 70 60 50], but get %v", keys)
+	}
+
+	keys = keys[:0]
+	r.AscendRange(55, 20, func(k, _ int) bool {
+		keys = append(keys, k)
+		return true
+	})
+	if fmt.Sprint(keys) != "[50 40 30]" {
+		t.Fatalf("error: reversed ascend range should [50 40 30], but get %v", keys)
+	}
+
+	if k, _, _ := r.Min(); k != 90 {
+		t.Fatalf("error: reversed min should 90, but get %v", k)
+	}
+	if k, _, _ := r.Max(); k != 0 {
+		t.Fatalf("error: reversed max should 0, but get %v", k)
+	}
+	if k, _, _ := r.Floor(55); k != 60 {
+		t.Fatalf("error: reversed floor 55 should 60, but get %v", k)
+	}
+	if k, _, _ := r.Ceiling(55); k != 50 {
+		t.Fatalf("error: reversed ceiling 55 should 50, but get %v", k)
+	}
+
+	// 与原来的红黑树共享数据
+	r.Put(95, 0)
+	r.Remove(0)
+	if k, _, _ := rbt.Max(); k != 95 || r.Len() != 10 || r.Reversed() != rbt {
+		t.Fatal("error: reversed view should share storage")
+	}
+	if _, ok := rbt.Get(0); ok {
+		t.Fatal("error: 0 should removed")
+	}
+}
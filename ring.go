@@ -0,0 +1,140 @@
+package rbtree
+
+import (
+	"hash/fnv"
+	"strconv"
+	"sync"
+)
+
+// Ring 是一致性哈希环，虚拟节点的哈希值作为红黑树的 key
+// 查找时取第一个大于等于 key 哈希值的虚拟节点，超过最大值时回到环的起点（最小的虚拟节点）
+type Ring struct {
+	mu       sync.RWMutex
+	tree     *RBTree[uint64, string]
+	replicas map[string]int
+	hash     func([]byte) uint64
+}
+
+// NewRing 创建哈希环，hash 为 nil 时使用 64 位的 FNV-1a
+func NewRing(hash func([]byte) uint64) *Ring {
+	if hash == nil {
+		hash = fnv64a
+	}
+	return &Ring{
+		tree:     NewRBTree[uint64, string](),
+		replicas: make(map[string]int),
+		hash:     hash,
+	}
+}
+
+// FNV-1a 对只有末尾几个字符不同的 key 分布不均匀，再用 murmur3 的 fmix64 打散
+func fnv64a(data []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(data)
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// 第 i 个虚拟节点的哈希值
+func (r *Ring) vnode(name string, i int) uint64 {
+	return r.hash([]byte(name + "#" + strconv.Itoa(i)))
+}
+
+// AddNode 添加节点及其 replicas 个虚拟节点，节点已存在时先移除原来的虚拟节点
+// 虚拟节点的哈希值冲突时，后添加的节点占据该位置
+func (r *Ring) AddNode(name string, replicas int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.removeNode(name)
+	if replicas <= 0 {
+		return
+	}
+	for i := 0; i < replicas; i++ {
+		r.tree.Put(r.vnode(name, i), name)
+	}
+	r.replicas[name] = replicas
+}
+
+func (r *Ring) RemoveNode(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.removeNode(name)
+}
+
+// 调用方需持有写锁
+func (r *Ring) removeNode(name string) bool {
+	replicas, ok := r.replicas[name]
+	if !ok {
+		return false
+	}
+	for i := 0; i < replicas; i++ {
+		h := r.vnode(name, i)
+		// 只删除仍属于该节点的位置
+		if owner, _ := r.tree.Get(h); owner == name {
+			r.tree.Remove(h)
+		}
+	}
+	delete(r.replicas, name)
+	return true
+}
+
+// Nodes 返回节点数量
+func (r *Ring) Nodes() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.replicas)
+}
+
+// Locate 返回 key 顺时针方向的第一个节点，环为空时返回空字符串
+func (r *Ring) Locate(key string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rbt := r.tree
+	n := rbt.ceiling(r.hash([]byte(key)))
+	if n == nil {
+		n = rbt.first()
+	}
+	if n == nil {
+		return ""
+	}
+	return n.value
+}
+
+// LocateN 返回 key 顺时针方向的前 n 个不同节点，可用于副本放置
+// 节点数量少于 n 时返回所有节点
+func (r *Ring) LocateN(key string, n int) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if n > len(r.replicas) {
+		n = len(r.replicas)
+	}
+	if n <= 0 {
+		return nil
+	}
+	rbt := r.tree
+	res := make([]string, 0, n)
+	seen := make(map[string]bool, n)
+	start := rbt.ceiling(r.hash([]byte(key)))
+	if start == nil {
+		start = rbt.first()
+	}
+	// 最多绕环一圈
+	for cur := start; len(res) < n; {
+		if !seen[cur.value] {
+			seen[cur.value] = true
+			res = append(res, cur.value)
+		}
+		if cur = rbt.successor(cur); cur == nil {
+			cur = rbt.first()
+		}
+		if cur == start {
+			break
+		}
+	}
+	return res
+}
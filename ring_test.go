@@ -0,0 +1,78 @@
+package rbtree
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	r := NewRing(nil)
+	if r.Locate("a") != "" || r.LocateN("a", 2) != nil {
+		t.Fatal("error: empty ring should locate nothing")
+	}
+	for _, name := range []string{"n1", "n2", "n3"} {
+		r.AddNode(name, 100)
+	}
+	if r.tree.Len() != 300 || r.Nodes() != 3 {
+		t.Fatalf("error: ring should have 300 vnodes, but get %v", r.tree.Len())
+	}
+
+	count := make(map[string]int)
+	located := make(map[string]string)
+	for i := 0; i < 3000; i++ {
+		key := fmt.Sprint("key", i)
+		node := r.Locate(key)
+		count[node]++
+		located[key] = node
+		nodes := r.LocateN(key, 5)
+		if len(nodes) != 3 || nodes[0] != node || nodes[1] == nodes[0] || nodes[2] == nodes[1] || nodes[2] == nodes[0] {
+			t.Fatalf("error: locate n of %v get %v", key, nodes)
+		}
+	}
+	for name, c := range count {
+		if c < 500 {
+			t.Fatalf("error: %v only get %v keys", name, c)
+		}
+	}
+
+	// 移除节点后只有原来属于该节点的 key 会移动
+	r.RemoveNode("n2")
+	if r.tree.Len() != 200 || r.RemoveNode("n2") {
+		t.Fatal("error: n2 should removed")
+	}
+	for key, old := range located {
+		node := r.Locate(key)
+		if old != "n2" && node != old || node == "n2" {
+			t.Fatalf("error: %v moved from %v to %v", key, old, node)
+		}
+	}
+}
+
+func TestRingWrapAround(t *testing.T) {
+	hash := func(b []byte) uint64 {
+		switch string(b) {
+		case "a#0":
+			return 10
+		case "b#0":
+			return 20
+		case "c#0":
+			return 30
+		}
+		var h uint64
+		fmt.Sscan(string(b), &h)
+		return h
+	}
+	r := NewRing(hash)
+	r.AddNode("a", 1)
+	r.AddNode("b", 1)
+	r.AddNode("c", 1)
+	cases := map[string]string{"5": "a", "10": "a", "11": "b", "30": "c", "31": "a"}
+	for key, want := range cases {
+		if got := r.Locate(key); got != want {
+			t.Fatalf("error: locate %v should %v, but get %v", key, want, got)
+		}
+	}
+	if got := fmt.Sprint(r.LocateN("25", 3)); got != "[c a b]" {
+		t.Fatalf("error: locate n should [c a b], but get %v", got)
+	}
+}
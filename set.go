@@ -0,0 +1,174 @@
+package rbtree
+
+import "golang.org/x/exp/constraints"
+
+// Set 是有序集合
+// 底层是 RBTree[K, struct{}]，struct{} 不占用空间，节点中没有额外的 value 字段
+type Set[K constraints.Ordered] struct {
+	tree *RBTree[K, struct{}]
+}
+
+func NewSet[K constraints.Ordered](keys ...K) *Set[K] {
+	s := &Set[K]{tree: NewRBTree[K, struct{}]()}
+	for _, k := range keys {
+		s.Add(k)
+	}
+	return s
+}
+
+// 由升序且不重复的 keys 在 O(n) 时间内构造集合
+func newSetSorted[K constraints.Ordered](keys []K) *Set[K] {
+	s := &Set[K]{tree: NewRBTree[K, struct{}]()}
+	s.tree.build(keys, make([]struct{}, len(keys)))
+	return s
+}
+
+// Add 添加 key，key 不存在时返回 true
+func (s *Set[K]) Add(key K) bool {
+	rbt := s.tree
+	rbt.mu.Lock()
+	defer rbt.mu.Unlock()
+	if _, n := rbt.search(key); n != nil {
+		return false
+	}
+	rbt.insert(key, struct{}{})
+	return true
+}
+
+func (s *Set[K]) Contains(key K) bool {
+	_, ok := s.tree.Get(key)
+	return ok
+}
+
+// Delete 删除 key，key 存在时返回 true
+func (s *Set[K]) Delete(key K) bool {
+	return s.tree.Remove(key)
+}
+
+func (s *Set[K]) Len() int {
+	return s.tree.Len()
+}
+
+// Ascend 按升序遍历，fn 返回 false 时停止
+func (s *Set[K]) Ascend(fn func(key K) bool) {
+	s.tree.Ascend(func(key K, _ struct{}) bool {
+		return fn(key)
+	})
+}
+
+// Keys 按升序返回所有 key
+func (s *Set[K]) Keys() []K {
+	rbt := s.tree
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	keys := make([]K, 0, rbt.size)
+	rbt.walk(func(n *node[K, struct{}]) bool {
+		keys = append(keys, n.key)
+		return true
+	})
+	return keys
+}
+
+func (s *Set[K]) Min() (K, bool) {
+	k, _, ok := s.tree.Min()
+	return k, ok
+}
+
+func (s *Set[K]) Max() (K, bool) {
+	k, _, ok := s.tree.Max()
+	return k, ok
+}
+
+// Floor 返回小于等于 key 的最大 key
+func (s *Set[K]) Floor(key K) (K, bool) {
+	k, _, ok := s.tree.Floor(key)
+	return k, ok
+}
+
+// Ceiling 返回大于等于 key 的最小 key
+func (s *Set[K]) Ceiling(key K) (K, bool) {
+	k, _, ok := s.tree.Ceiling(key)
+	return k, ok
+}
+
+// 集合运算先分别取出两个集合的有序 key，再归并，结果在 O(n + m) 时间内构造
+// 分别加锁取出 key 可以避免同时持有两个集合的锁导致死锁（包括 s 和 other 是同一个集合）
+// onlyA、onlyB、both 分别决定只在 s 中、只在 other 中、两者都有的 key 是否保留
+func (s *Set[K]) merge(other *Set[K], onlyA, onlyB, both bool) *Set[K] {
+	a, b := s.Keys(), other.Keys()
+	res := make([]K, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j == len(b) || i < len(a) && a[i] < b[j]:
+			if onlyA {
+				res = append(res, a[i])
+			}
+			i++
+		case i == len(a) || b[j] < a[i]:
+			if onlyB {
+				res = append(res, b[j])
+			}
+			j++
+		default:
+			if both {
+				res = append(res, a[i])
+			}
+			i++
+			j++
+		}
+	}
+	return newSetSorted(res)
+}
+
+// Union 返回 s ∪ other
+func (s *Set[K]) Union(other *Set[K]) *Set[K] {
+	return s.merge(other, true, true, true)
+}
+
+// Intersect 返回 s ∩ other
+func (s *Set[K]) Intersect(other *Set[K]) *Set[K] {
+	return s.merge(other, false, false, true)
+}
+
+// Difference 返回 s - other
+func (s *Set[K]) Difference(other *Set[K]) *Set[K] {
+	return s.merge(other, true, false, false)
+}
+
+// SymmetricDifference 返回只在其中一个集合中的 key
+func (s *Set[K]) SymmetricDifference(other *Set[K]) *Set[K] {
+	return s.merge(other, true, true, false)
+}
+
+// IsSubset 判断 s 是否是 other 的子集
+func (s *Set[K]) IsSubset(other *Set[K]) bool {
+	a, b := s.Keys(), other.Keys()
+	if len(a) > len(b) {
+		return false
+	}
+	j := 0
+	for _, k := range a {
+		for j < len(b) && b[j] < k {
+			j++
+		}
+		if j == len(b) || b[j] != k {
+			return false
+		}
+		j++
+	}
+	return true
+}
+
+func (s *Set[K]) Equal(other *Set[K]) bool {
+	a, b := s.Keys(), other.Keys()
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
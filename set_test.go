@@ -0,0 +1,93 @@
+package rbtree
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	s := NewSet(5, 1, 3)
+	if !s.Add(4) || s.Add(4) {
+		t.Fatal("error: add 4 should only succeed once")
+	}
+	if !s.Contains(3) || s.Contains(2) {
+		t.Fatal("error: set should contain 3 but not 2")
+	}
+	if !s.Delete(3) || s.Delete(3) {
+		t.Fatal("error: delete 3 should only succeed once")
+	}
+	if fmt.Sprint(s.Keys()) != "[1 4 5]" || s.Len() != 3 {
+		t.Fatalf("error: keys should [1 4 5], but get %v", s.Keys())
+	}
+	if k, ok := s.Floor(3); !ok || k != 1 {
+		t.Fatalf("error: floor 3 should 1, but get %v", k)
+	}
+	if k, ok := s.Ceiling(3); !ok || k != 4 {
+		t.Fatalf("error: ceiling 3 should 4, but get %v", k)
+	}
+	if _, ok := s.Ceiling(6); ok {
+		t.Fatal("error: ceiling 6 should not exist")
+	}
+	if k, _ := s.Min(); k != 1 {
+		t.Fatalf("error: min should 1, but get %v", k)
+	}
+	if k, _ := s.Max(); k != 5 {
+		t.Fatalf("error: max should 5, but get %v", k)
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	a := NewSet(1, 2, 3, 4)
+	b := NewSet(3, 4, 5)
+	cases := []struct {
+		name string
+		set  *Set[int]
+		want string
+	}{
+		{"union", a.Union(b), "[1 2 3 4 5]"},
+		{"intersect", a.Intersect(b), "[3 4]"},
+		{"difference", a.Difference(b), "[1 2]"},
+		{"symmetric difference", a.SymmetricDifference(b), "[1 2 5]"},
+		{"self union", a.Union(a), "[1 2 3 4]"},
+	}
+	for _, c := range cases {
+		checkRBTree(t, c.set.tree)
+		if got := fmt.Sprint(c.set.Keys()); got != c.want {
+			t.Fatalf("error: %v should %v, but get %v", c.name, c.want, got)
+		}
+	}
+	if !a.Intersect(b).IsSubset(b) || a.IsSubset(b) || !NewSet[int]().IsSubset(a) {
+		t.Fatal("error: wrong subset result")
+	}
+	if !a.Equal(NewSet(4, 3, 2, 1)) || a.Equal(b) {
+		t.Fatal("error: wrong equal result")
+	}
+}
+
+func TestSetRandomOperations(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	a, b := NewSet[int](), NewSet[int]()
+	ma, mb := map[int]bool{}, map[int]bool{}
+	for i := 0; i < 500; i++ {
+		x, y := r.Intn(300), r.Intn(300)
+		a.Add(x)
+		b.Add(y)
+		ma[x], mb[y] = true, true
+	}
+	union := a.Union(b)
+	inter := a.Intersect(b)
+	diff := a.Difference(b)
+	sym := a.SymmetricDifference(b)
+	for k := 0; k < 300; k++ {
+		if union.Contains(k) != (ma[k] || mb[k]) ||
+			inter.Contains(k) != (ma[k] && mb[k]) ||
+			diff.Contains(k) != (ma[k] && !mb[k]) ||
+			sym.Contains(k) != (ma[k] != mb[k]) {
+			t.Fatalf("error: wrong set operation result of %v", k)
+		}
+	}
+	for _, s := range []*Set[int]{union, inter, diff, sym} {
+		checkRBTree(t, s.tree)
+	}
+}
@@ -0,0 +1,76 @@
+package rbtree
+
+import "unsafe"
+
+// Stats 是红黑树的结构统计，深度从 1 开始计算（根节点深度为 1）
+type Stats struct {
+	Size int
+	// 最深节点的深度
+	Height int
+	// 从根节点到任意 nil 叶子节点路径上的黑色节点数（不包括叶子节点）
+	BlackHeight int
+	// 没有子节点的节点的最小、最大深度
+	MinDepth int
+	MaxDepth int
+	// 所有节点的平均深度
+	AvgDepth   float64
+	RedNodes   int
+	BlackNodes int
+	// 估算的内存占用：节点、叶子节点和 RBTree 本身的大小，
+	// 不包括 key/value 中指针引用的内存（如字符串内容、切片底层数组）
+	MemoryBytes int
+}
+
+// Stats 在读锁下遍历整棵树计算统计信息，O(n)
+func (rbt *RBTree[K, V]) Stats() Stats {
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	s := Stats{
+		Size:        rbt.size,
+		MemoryBytes: int(unsafe.Sizeof(*rbt)),
+	}
+	if rbt.leaf != nil {
+		s.MemoryBytes += int(unsafe.Sizeof(*rbt.leaf)) * (rbt.size + 1)
+	}
+	if rbt.root == nil {
+		return s
+	}
+
+	totalDepth := 0
+	var walk func(n *node[K, V], depth int)
+	walk = func(n *node[K, V], depth int) {
+		totalDepth += depth
+		if n.color == red {
+			s.RedNodes++
+		} else {
+			s.BlackNodes++
+		}
+		if depth > s.Height {
+			s.Height = depth
+		}
+		if n.left == rbt.leaf && n.right == rbt.leaf {
+			if s.MinDepth == 0 || depth < s.MinDepth {
+				s.MinDepth = depth
+			}
+			if depth > s.MaxDepth {
+				s.MaxDepth = depth
+			}
+		}
+		if n.left != rbt.leaf {
+			walk(n.left, depth+1)
+		}
+		if n.right != rbt.leaf {
+			walk(n.right, depth+1)
+		}
+	}
+	walk(rbt.root, 1)
+	s.AvgDepth = float64(totalDepth) / float64(rbt.size)
+
+	// 所有路径的黑色节点数相同，沿最左路径计算即可
+	for n := rbt.root; n != rbt.leaf; n = n.left {
+		if n.color == black {
+			s.BlackHeight++
+		}
+	}
+	return s
+}
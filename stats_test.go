@@ -0,0 +1,52 @@
+package rbtree
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestRBTreeStats(t *testing.T) {
+	rbt := NewRBTree[int, int]()
+	if s := rbt.Stats(); s.Size != 0 || s.Height != 0 || s.BlackHeight != 0 {
+		t.Fatalf("error: empty rbtree stats get %+v", s)
+	}
+
+	//            25(b)
+	//        /         \
+	//      20(b)      50(b)
+	//    /
+	//  13(r)
+	for _, k := range []int{25, 20, 50, 13} {
+		rbt.Put(k, 0)
+	}
+	s := rbt.Stats()
+	want := Stats{
+		Size:        4,
+		Height:      3,
+		BlackHeight: 2,
+		MinDepth:    2,
+		MaxDepth:    3,
+		AvgDepth:    2,
+		RedNodes:    1,
+		BlackNodes:  3,
+		MemoryBytes: int(unsafe.Sizeof(*rbt)) + 5*int(unsafe.Sizeof(node[int, int]{})),
+	}
+	if s != want {
+		t.Fatalf("error: stats should %+v, but get %+v", want, s)
+	}
+}
+
+func TestRBTreeStatsLarge(t *testing.T) {
+	rbt := NewRBTree[int, int]()
+	for i := 0; i < 100000; i++ {
+		rbt.Put(i, i)
+	}
+	s := rbt.Stats()
+	if s.RedNodes+s.BlackNodes != s.Size || s.Size != 100000 {
+		t.Fatalf("error: node count get %+v", s)
+	}
+	// 红黑树的高度不超过 2log(n+1)
+	if s.Height > 2*17 || s.Height > 2*s.BlackHeight || s.MinDepth < s.BlackHeight {
+		t.Fatalf("error: unbalanced rbtree %+v", s)
+	}
+}
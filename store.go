@@ -0,0 +1,405 @@
+package rbtree
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/exp/constraints"
+)
+
+// 持久化目录中的文件
+const (
+	snapshotFile = "snapshot.rbt"
+	walFile      = "wal.log"
+)
+
+// WAL 记录的操作类型
+const (
+	walPut byte = iota + 1
+	walRemove
+)
+
+// WAL 记录头：payload 长度(uint32) | payload 的 crc32(uint32)
+// payload：op(1 byte) | key | value(仅 put)
+const walHeaderSize = 8
+
+var (
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+	ErrCorruptSnapshot = errors.New("rbtree: corrupt snapshot")
+	ErrCorruptWAL      = errors.New("rbtree: corrupt WAL")
+	ErrClosed          = errors.New("rbtree: store is closed")
+	ErrStoreFailed     = errors.New("rbtree: store failed")
+)
+
+// WAL 文件，测试中替换为会出错的实现
+type walWriter interface {
+	io.Writer
+	io.Seeker
+	io.Closer
+	Truncate(size int64) error
+	Sync() error
+}
+
+type StoreOptions[K constraints.Ordered, V any] struct {
+	// 每写入多少条 WAL 记录自动生成一次快照，0 表示只在调用 Snapshot 时生成
+	SnapshotEvery int
+	// 每条 WAL 记录写入后是否 fsync
+	Sync bool
+	// key、value 的编解码器，nil 表示使用 DefaultCodec
+	KeyCodec   Codec[K]
+	ValueCodec Codec[V]
+}
+
+// Store 是带持久化的红黑树
+// 每次 Put/Remove 先追加到 WAL，再修改内存中的红黑树；
+// 快照是 MarshalBinary 的输出加上 crc32，生成快照后清空 WAL
+type Store[K constraints.Ordered, V any] struct {
+	mu   sync.Mutex
+	tree *RBTree[K, V]
+	dir  string
+	wal  walWriter
+	// WAL 中完整记录的总长度，写入失败时截断到这里
+	walSize int64
+	records int
+	opts    StoreOptions[K, V]
+	buf     []byte
+	// 写入失败且无法回滚时设置，之后拒绝所有写入，直到 Snapshot 成功
+	failed error
+	// 最近一次自动快照的错误
+	snapshotErr error
+}
+
+// Open 打开 dir 中的持久化红黑树，目录不存在时创建
+// 恢复时先加载最新的快照，再重放 WAL；WAL 末尾不完整或校验失败的最后一条记录
+// （写入时进程崩溃导致）会被截断丢弃，其他位置的损坏记录返回 ErrCorruptWAL，不修改文件
+func Open[K constraints.Ordered, V any](dir string, opts *StoreOptions[K, V]) (*Store[K, V], error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	s := &Store[K, V]{
+		tree: NewRBTree[K, V](),
+		dir:  dir,
+	}
+	if opts != nil {
+		s.opts = *opts
+	}
+	s.tree.SetCodec(s.opts.KeyCodec, s.opts.ValueCodec)
+
+	if err := s.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	if err := s.replay(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store[K, V]) loadSnapshot() error {
+	data, err := os.ReadFile(filepath.Join(s.dir, snapshotFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(data) < 4 {
+		return ErrCorruptSnapshot
+	}
+	body, sum := data[:len(data)-4], binary.LittleEndian.Uint32(data[len(data)-4:])
+	if crc32.Checksum(body, crcTable) != sum {
+		return ErrCorruptSnapshot
+	}
+	return s.tree.UnmarshalBinary(body)
+}
+
+func (s *Store[K, V]) replay() error {
+	f, err := os.OpenFile(filepath.Join(s.dir, walFile), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	kc, vc := s.tree.codecs()
+	offset := 0
+	for {
+		rest := data[offset:]
+		if len(rest) < walHeaderSize {
+			break
+		}
+		l := binary.LittleEndian.Uint32(rest)
+		sum := binary.LittleEndian.Uint32(rest[4:])
+		if uint64(len(rest)-walHeaderSize) < uint64(l) {
+			break
+		}
+		payload := rest[walHeaderSize : walHeaderSize+int(l)]
+		if crc32.Checksum(payload, crcTable) != sum {
+			// 只有最后一条记录可能是写入时崩溃留下的，之后还有数据说明日志损坏，
+			// 截断会丢弃已经确认的写入
+			if walHeaderSize+int(l) == len(rest) {
+				break
+			}
+			f.Close()
+			return fmt.Errorf("%w: bad checksum at offset %d", ErrCorruptWAL, offset)
+		}
+		if err := s.apply(payload, kc, vc); err != nil {
+			f.Close()
+			return fmt.Errorf("%w: bad record at offset %d: %v", ErrCorruptWAL, offset, err)
+		}
+		offset += walHeaderSize + int(l)
+		s.records++
+	}
+	// 截断末尾损坏的记录，后续的写入从完整记录之后开始
+	if offset != len(data) {
+		if err := f.Truncate(int64(offset)); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if _, err := f.Seek(int64(offset), io.SeekStart); err != nil {
+		f.Close()
+		return err
+	}
+	s.wal = f
+	s.walSize = int64(offset)
+	return nil
+}
+
+func (s *Store[K, V]) apply(payload []byte, kc Codec[K], vc Codec[V]) error {
+	if len(payload) == 0 {
+		return ErrInvalidData
+	}
+	op, payload := payload[0], payload[1:]
+	key, n, err := kc.Decode(payload)
+	if err != nil {
+		return err
+	}
+	payload = payload[n:]
+	switch op {
+	case walPut:
+		value, n, err := vc.Decode(payload)
+		if err != nil {
+			return err
+		}
+		if n != len(payload) {
+			return ErrInvalidData
+		}
+		s.tree.Put(key, value)
+	case walRemove:
+		if len(payload) != 0 {
+			return ErrInvalidData
+		}
+		s.tree.Remove(key)
+	default:
+		return ErrInvalidData
+	}
+	return nil
+}
+
+// 调用方需持有 s.mu
+func (s *Store[K, V]) append(op byte, key K, value V) error {
+	if s.wal == nil {
+		return ErrClosed
+	}
+	if s.failed != nil {
+		return s.failed
+	}
+	kc, vc := s.tree.codecs()
+	buf := append(s.buf[:0], make([]byte, walHeaderSize)...)
+	buf = append(buf, op)
+	buf, err := kc.Append(buf, key)
+	if err != nil {
+		return err
+	}
+	if op == walPut {
+		if buf, err = vc.Append(buf, value); err != nil {
+			return err
+		}
+	}
+	payload := buf[walHeaderSize:]
+	binary.LittleEndian.PutUint32(buf, uint32(len(payload)))
+	binary.LittleEndian.PutUint32(buf[4:], crc32.Checksum(payload, crcTable))
+	s.buf = buf
+
+	if err := s.writeRecord(buf); err != nil {
+		// 写入了一部分的记录会让重放在这里停止，丢弃之后已经成功的写入，
+		// 必须截断回写入之前的位置
+		if rerr := s.rollback(); rerr != nil {
+			s.failed = fmt.Errorf("%w: %v", ErrStoreFailed, rerr)
+		}
+		return err
+	}
+	s.walSize += int64(len(buf))
+	s.records++
+	return nil
+}
+
+func (s *Store[K, V]) writeRecord(buf []byte) error {
+	if _, err := s.wal.Write(buf); err != nil {
+		return err
+	}
+	if s.opts.Sync {
+		return s.wal.Sync()
+	}
+	return nil
+}
+
+func (s *Store[K, V]) rollback() error {
+	if err := s.wal.Truncate(s.walSize); err != nil {
+		return err
+	}
+	_, err := s.wal.Seek(s.walSize, io.SeekStart)
+	return err
+}
+
+// 调用方需持有 s.mu
+// 写入已经生效，自动快照失败不作为写入的错误返回，记录在 SnapshotErr 中，
+// 下一次写入时重试
+func (s *Store[K, V]) maybeSnapshot() {
+	if s.opts.SnapshotEvery > 0 && s.records >= s.opts.SnapshotEvery {
+		s.snapshotErr = s.snapshot()
+	}
+}
+
+// SnapshotErr 返回最近一次自动快照的错误，自动快照成功后为 nil
+func (s *Store[K, V]) SnapshotErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshotErr
+}
+
+func (s *Store[K, V]) Put(key K, value V) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.append(walPut, key, value); err != nil {
+		return err
+	}
+	s.tree.Put(key, value)
+	s.maybeSnapshot()
+	return nil
+}
+
+func (s *Store[K, V]) Remove(key K) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.tree.Get(key); !ok {
+		return false, nil
+	}
+	var zero V
+	if err := s.append(walRemove, key, zero); err != nil {
+		return false, err
+	}
+	s.tree.Remove(key)
+	s.maybeSnapshot()
+	return true, nil
+}
+
+func (s *Store[K, V]) Get(key K) (V, bool) {
+	return s.tree.Get(key)
+}
+
+// Tree 返回内存中的红黑树，只能用于读取，直接修改不会写入 WAL
+func (s *Store[K, V]) Tree() *RBTree[K, V] {
+	return s.tree
+}
+
+// Snapshot 生成快照并清空 WAL，成功后 Store 从写入失败的状态中恢复
+func (s *Store[K, V]) Snapshot() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.wal == nil {
+		return ErrClosed
+	}
+	return s.snapshot()
+}
+
+// 先写临时文件再 rename，保证快照文件总是完整的
+// 如果在 rename 之后、清空 WAL 之前崩溃，恢复时会在新快照上重放旧的 WAL，
+// 按顺序重放 Put/Remove 的结果与直接加载快照一致
+func (s *Store[K, V]) snapshot() error {
+	s.tree.mu.RLock()
+	data, err := s.tree.appendBinary(nil)
+	s.tree.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	var sum [4]byte
+	binary.LittleEndian.PutUint32(sum[:], crc32.Checksum(data, crcTable))
+	data = append(data, sum[:]...)
+
+	tmp := filepath.Join(s.dir, snapshotFile+".tmp")
+	if err := writeFileSync(tmp, data); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, snapshotFile)); err != nil {
+		return err
+	}
+	if err := syncDir(s.dir); err != nil {
+		return err
+	}
+
+	if err := s.wal.Truncate(0); err != nil {
+		return err
+	}
+	s.walSize = 0
+	if _, err := s.wal.Seek(0, io.SeekStart); err != nil {
+		// 写入位置未知，不能继续追加
+		s.failed = fmt.Errorf("%w: %v", ErrStoreFailed, err)
+		return err
+	}
+	s.records = 0
+	s.failed = nil
+	return s.wal.Sync()
+}
+
+func (s *Store[K, V]) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.wal == nil {
+		return ErrClosed
+	}
+	err := s.wal.Sync()
+	if cerr := s.wal.Close(); err == nil {
+		err = cerr
+	}
+	s.wal = nil
+	return err
+}
+
+func writeFileSync(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,315 @@
+package rbtree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStoreRecover(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open[int, string](dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if err := s.Put(i, string(rune('a'+i%26))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 100; i += 2 {
+		if ok, err := s.Remove(i); !ok || err != nil {
+			t.Fatalf("error: remove %v should succeed, but get %v", i, err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Open[int, string](dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if checkRBTree(t, s.Tree()) != 50 {
+		t.Fatal("error: recovered rbtree size should 50")
+	}
+	if v, ok := s.Get(31); !ok || v != "f" {
+		t.Fatalf("error: get 31 should f, but get %v", v)
+	}
+	if _, ok := s.Get(30); ok {
+		t.Fatal("error: 30 should removed")
+	}
+}
+
+func TestStoreSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, &StoreOptions[string, int]{SnapshotEvery: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 25; i++ {
+		s.Put(string(rune('a'+i)), i)
+	}
+	if s.records != 5 {
+		t.Fatalf("error: wal records should 5, but get %v", s.records)
+	}
+	s.Close()
+
+	if _, err := os.Stat(filepath.Join(dir, snapshotFile)); err != nil {
+		t.Fatal(err)
+	}
+	s, err = Open[string, int](dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if checkRBTree(t, s.Tree()) != 25 {
+		t.Fatal("error: recovered rbtree size should 25")
+	}
+}
+
+func TestStoreSnapshotBeforeTruncate(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := Open[int, int](dir, nil)
+	s.Put(1, 1)
+	s.Put(2, 2)
+	s.Remove(1)
+	s.Put(1, 10)
+	s.Close()
+	wal, _ := os.ReadFile(filepath.Join(dir, walFile))
+
+	// 模拟生成快照后、清空 WAL 前崩溃
+	s, _ = Open[int, int](dir, nil)
+	s.Snapshot()
+	s.Close()
+	os.WriteFile(filepath.Join(dir, walFile), wal, 0o644)
+
+	s, err := Open[int, int](dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if checkRBTree(t, s.Tree()) != 2 {
+		t.Fatal("error: recovered rbtree size should 2")
+	}
+	if v, _ := s.Get(1); v != 10 {
+		t.Fatalf("error: get 1 should 10, but get %v", v)
+	}
+}
+
+func TestStoreTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := Open[int, int](dir, nil)
+	for i := 0; i < 10; i++ {
+		s.Put(i, i)
+	}
+	s.Close()
+
+	name := filepath.Join(dir, walFile)
+	wal, _ := os.ReadFile(name)
+	// 最后一条记录只写入了一部分
+	os.WriteFile(name, wal[:len(wal)-2], 0o644)
+
+	s, err := Open[int, int](dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkRBTree(t, s.Tree()) != 9 {
+		t.Fatal("error: recovered rbtree size should 9")
+	}
+	// 截断后继续写入的记录可以被正常恢复
+	s.Put(100, 100)
+	s.Close()
+
+	s, _ = Open[int, int](dir, nil)
+	defer s.Close()
+	if checkRBTree(t, s.Tree()) != 10 {
+		t.Fatal("error: recovered rbtree size should 10")
+	}
+	if _, ok := s.Get(100); !ok {
+		t.Fatal("error: 100 should exist")
+	}
+}
+
+func TestStoreCorruptRecord(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := Open[int, int](dir, nil)
+	for i := 0; i < 10; i++ {
+		s.Put(i, i)
+	}
+	s.Close()
+
+	name := filepath.Join(dir, walFile)
+	wal, _ := os.ReadFile(name)
+	size := len(wal) / 10
+
+	// 最后一条记录校验失败，视为写入时崩溃，截断
+	last := append([]byte(nil), wal...)
+	last[len(last)-1] ^= 0xff
+	os.WriteFile(name, last, 0o644)
+	s, err := Open[int, int](dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkRBTree(t, s.Tree()) != 9 {
+		t.Fatal("error: recovered rbtree size should 9")
+	}
+	s.Close()
+
+	// 中间的记录损坏，不能截断之后已经确认的写入
+	mid := append([]byte(nil), wal...)
+	mid[size+walHeaderSize+1] ^= 0xff
+	os.WriteFile(name, mid, 0o644)
+	if _, err := Open[int, int](dir, nil); !errors.Is(err, ErrCorruptWAL) {
+		t.Fatalf("error: corrupt record should return ErrCorruptWAL, but get %v", err)
+	}
+	if got, _ := os.ReadFile(name); !bytes.Equal(got, mid) {
+		t.Fatal("error: corrupt WAL should be left untouched")
+	}
+
+	// 长度正确、校验通过但无法解码的记录
+	bad := append([]byte(nil), wal...)
+	payload := []byte{0xff}
+	binary.LittleEndian.PutUint32(bad[4:], crc32.Checksum(payload, crcTable))
+	binary.LittleEndian.PutUint32(bad, 1)
+	bad = append(bad[:walHeaderSize+1], wal[size:]...)
+	bad[walHeaderSize] = 0xff
+	os.WriteFile(name, bad, 0o644)
+	if _, err := Open[int, int](dir, nil); !errors.Is(err, ErrCorruptWAL) {
+		t.Fatalf("error: undecodable record should return ErrCorruptWAL, but get %v", err)
+	}
+}
+
+func TestStoreCorruptSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := Open[int, int](dir, nil)
+	s.Put(1, 1)
+	s.Snapshot()
+	s.Close()
+
+	name := filepath.Join(dir, snapshotFile)
+	data, _ := os.ReadFile(name)
+	data[len(data)-5] ^= 0xff
+	os.WriteFile(name, data, 0o644)
+	if _, err := Open[int, int](dir, nil); err != ErrCorruptSnapshot {
+		t.Fatalf("error: open should return ErrCorruptSnapshot, but get %v", err)
+	}
+}
+
+func TestStoreClosed(t *testing.T) {
+	s, _ := Open[int, int](t.TempDir(), nil)
+	s.Close()
+	if err := s.Put(1, 1); err != ErrClosed {
+		t.Fatalf("error: put after close should return ErrClosed, but get %v", err)
+	}
+}
+
+var errDiskFull = errors.New("disk full")
+
+// 可以按需出错的 WAL，Write 出错时已经写入了一半的数据
+type failingWAL struct {
+	walWriter
+	failWrite, failTruncate bool
+}
+
+func (f *failingWAL) Write(p []byte) (int, error) {
+	if f.failWrite {
+		n, _ := f.walWriter.Write(p[:len(p)/2])
+		return n, errDiskFull
+	}
+	return f.walWriter.Write(p)
+}
+
+func (f *failingWAL) Truncate(size int64) error {
+	if f.failTruncate {
+		return errDiskFull
+	}
+	return f.walWriter.Truncate(size)
+}
+
+func TestStoreWriteFailure(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open[int, string](dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wal := &failingWAL{walWriter: s.wal}
+	s.wal = wal
+	s.Put(1, "a")
+	wal.failWrite = true
+	if err := s.Put(2, "b"); err != errDiskFull {
+		t.Fatalf("error: put should fail with disk full, but get %v", err)
+	}
+	if _, ok := s.Get(2); ok {
+		t.Fatal("error: failed put should not be applied")
+	}
+	// 回滚了写入一半的记录，之后的写入在重启后仍然存在
+	wal.failWrite = false
+	if err := s.Put(3, "c"); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	s, err = Open[int, string](dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, ok := s.Get(3); !ok || s.Tree().Len() != 2 {
+		t.Fatalf("error: acknowledged writes should survive, but get %v keys", s.Tree().Len())
+	}
+
+	// 无法回滚时拒绝之后的写入
+	wal = &failingWAL{walWriter: s.wal, failWrite: true, failTruncate: true}
+	s.wal = wal
+	if err := s.Put(4, "d"); err != errDiskFull {
+		t.Fatalf("error: put should fail with disk full, but get %v", err)
+	}
+	wal.failWrite = false
+	if err := s.Put(5, "e"); !errors.Is(err, ErrStoreFailed) {
+		t.Fatalf("error: failed store should refuse writes, but get %v", err)
+	}
+	if _, err := s.Remove(1); !errors.Is(err, ErrStoreFailed) {
+		t.Fatalf("error: failed store should refuse writes, but get %v", err)
+	}
+	// 快照清空 WAL 后恢复
+	wal.failTruncate = false
+	if err := s.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(5, "e"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStoreSnapshotFailure(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open[int, string](dir, &StoreOptions[int, string]{SnapshotEvery: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	// 临时快照文件的位置被目录占用，无法生成快照
+	tmp := filepath.Join(dir, snapshotFile+".tmp")
+	if err := os.Mkdir(tmp, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(1, "a"); err != nil {
+		t.Fatalf("error: put should succeed when snapshot fails, but get %v", err)
+	}
+	if ok, err := s.Remove(1); !ok || err != nil {
+		t.Fatalf("error: remove should succeed when snapshot fails, but get %v", err)
+	}
+	if s.SnapshotErr() == nil {
+		t.Fatal("error: snapshot error should be recorded")
+	}
+	os.Remove(tmp)
+	if err := s.Put(2, "b"); err != nil || s.SnapshotErr() != nil {
+		t.Fatalf("error: snapshot should succeed, but get %v %v", err, s.SnapshotErr())
+	}
+}
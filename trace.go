@@ -0,0 +1,101 @@
+package rbtree
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+// 跟踪事件的类型
+type TraceKind byte
+
+const (
+	// 插入调整时进入的情况，见 insertAdjust 中的 case 1 ~ case 3.2.2
+	TraceInsertCase TraceKind = iota
+	// 删除节点时的情况：1 没有子节点，2 只有一个子节点，3 左右子节点都存在
+	TraceRemoveCase
+	// 删除调整时进入的情况，见 deleteAdjust 中的 case 2、case 1.1 ~ case 1.3
+	// 当前节点是右子节点时为对称的情况，编号相同
+	TraceFixCase
+	TraceRotateLeft
+	TraceRotateRight
+	TraceRecolor
+)
+
+func (k TraceKind) String() string {
+	switch k {
+	case TraceInsertCase:
+		return "insert case"
+	case TraceRemoveCase:
+		return "remove case"
+	case TraceFixCase:
+		return "fix case"
+	case TraceRotateLeft:
+		return "rotate left"
+	case TraceRotateRight:
+		return "rotate right"
+	case TraceRecolor:
+		return "recolor"
+	}
+	return fmt.Sprintf("TraceKind(%d)", k)
+}
+
+// TraceEvent 是插入或删除过程中的一步
+type TraceEvent[K constraints.Ordered] struct {
+	Op   Op
+	Kind TraceKind
+	// 情况的编号，如 "3.1.2"，只有 TraceInsertCase/TraceRemoveCase/TraceFixCase 有
+	Case string
+	// 事件作用的节点：插入调整时为当前节点，删除时为被删除的节点，
+	// 删除调整时为当前节点的父节点（当前节点可能是 leaf），旋转时为旋转的支点
+	Key K
+	// 事件之后节点是否为红色
+	Red bool
+}
+
+func (e TraceEvent[K]) String() string {
+	c := black
+	if e.Red {
+		c = red
+	}
+	switch e.Kind {
+	case TraceInsertCase, TraceRemoveCase, TraceFixCase:
+		return fmt.Sprintf("%v %s at %v", e.Kind, e.Case, e.Key)
+	case TraceRecolor:
+		return fmt.Sprintf("%v %v -> %v", e.Kind, e.Key, c)
+	}
+	return fmt.Sprintf("%v at %v", e.Kind, e.Key)
+}
+
+// Tracer 按顺序接收插入和删除过程中的每一步，调用时持有写锁，
+// 实现中不能访问红黑树
+type Tracer[K constraints.Ordered] interface {
+	Trace(e TraceEvent[K])
+}
+
+// TraceFunc 把普通函数转换为 Tracer
+type TraceFunc[K constraints.Ordered] func(e TraceEvent[K])
+
+func (f TraceFunc[K]) Trace(e TraceEvent[K]) {
+	f(e)
+}
+
+// SetTracer 设置 Tracer，传 nil 取消
+func (rbt *RBTree[K, V]) SetTracer(t Tracer[K]) {
+	rbt.mu.Lock()
+	defer rbt.mu.Unlock()
+	rbt.tracer = t
+}
+
+func (rbt *RBTree[K, V]) trace(kind TraceKind, c string, n *node[K, V]) {
+	if rbt.tracer == nil {
+		return
+	}
+	rbt.tracer.Trace(TraceEvent[K]{
+		Op:   rbt.opStats.Op,
+		Kind: kind,
+		Case: c,
+		Key:  n.key,
+		Red:  n.color == red,
+	})
+}
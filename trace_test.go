@@ -0,0 +1,88 @@
+package rbtree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRBTreeTracer(t *testing.T) {
+	rbt := NewRBTree[int, int]()
+	var events []string
+	var ops []Op
+	rbt.SetTracer(TraceFunc[int](func(e TraceEvent[int]) {
+		events = append(events, e.String())
+		ops = append(ops, e.Op)
+	}))
+	rbt.Put(10, 0)
+	rbt.Put(20, 0)
+	rbt.Put(30, 0)
+	rbt.Put(30, 1)
+	rbt.Remove(20)
+	want := []string{
+		"insert case 1 at 10",
+		"insert case 1 at 20",
+		// case 3.2.1: 祖父节点左旋，父节点和祖父节点变色
+		"insert case 3.2.1 at 30",
+		"rotate left at 10",
+		"recolor 20 -> b",
+		"recolor 10 -> r",
+		// 前驱 10 顶替 20 并继承它的颜色，被移走的是红色节点，无需调整
+		"remove case 3 at 20",
+	}
+	if len(events) != len(want) {
+		t.Fatalf("error: trace should %q, but get %q", want, events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Fatalf("error: event %v should %q, but get %q", i, want[i], events[i])
+		}
+	}
+
+	if ops[5] != OpInsert || ops[6] != OpDelete {
+		t.Fatalf("error: trace ops get %v", ops)
+	}
+
+	rbt.SetTracer(nil)
+	rbt.Put(1, 1)
+	if len(events) != len(want) {
+		t.Fatal("error: removed tracer should not called")
+	}
+}
+
+// 跟踪到的旋转和变色次数与 Observer 统计的一致
+func TestRBTreeTracerMatchesObserver(t *testing.T) {
+	rbt := NewRBTree[int, int]()
+	var traced OpStats
+	rbt.SetTracer(TraceFunc[int](func(e TraceEvent[int]) {
+		switch e.Kind {
+		case TraceRotateLeft:
+			traced.LeftRotations++
+		case TraceRotateRight:
+			traced.RightRotations++
+		case TraceRecolor:
+			traced.Recolors++
+		}
+	}))
+	var rec recordObserver
+	rbt.SetObserver(&rec)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		k := r.Intn(500)
+		if r.Intn(3) == 0 {
+			rbt.Remove(k)
+		} else {
+			rbt.Put(k, i)
+		}
+		var got OpStats
+		for _, s := range rec {
+			got.LeftRotations += s.LeftRotations
+			got.RightRotations += s.RightRotations
+			got.Recolors += s.Recolors
+		}
+		rec = rec[:0]
+		if got != traced {
+			t.Fatalf("error: traced %+v should match observed %+v", traced, got)
+		}
+		traced = OpStats{}
+	}
+}
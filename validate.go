@@ -0,0 +1,86 @@
+package rbtree
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidTree = errors.New("rbtree: invalid tree")
+
+// Validate 检查红黑树的所有约束：
+// 根节点和叶子节点是黑色，红色节点没有红色子节点，每条路径上的黑色节点数相同，
+// key 严格按中序递增，父子指针一致，以及节点数与 Len 相同
+// 返回的错误包装了 ErrInvalidTree
+func (rbt *RBTree[K, V]) Validate() error {
+	rbt.mu.RLock()
+	defer rbt.mu.RUnlock()
+	return rbt.validate()
+}
+
+func (rbt *RBTree[K, V]) validate() error {
+	if rbt.root == nil {
+		if rbt.size != 0 {
+			return fmt.Errorf("%w: empty tree has size %d", ErrInvalidTree, rbt.size)
+		}
+		return nil
+	}
+	if rbt.leaf == nil || rbt.leaf.color != black {
+		return fmt.Errorf("%w: leaf is not black", ErrInvalidTree)
+	}
+	if rbt.root.color != black {
+		return fmt.Errorf("%w: root %v is red", ErrInvalidTree, rbt.root.key)
+	}
+	if rbt.root.parent != nil {
+		return fmt.Errorf("%w: root %v has parent", ErrInvalidTree, rbt.root.key)
+	}
+
+	cnt := 0
+	// lo/hi 为祖先中 key 的上下界，返回黑高
+	var walk func(n, lo, hi *node[K, V]) (int, error)
+	walk = func(n, lo, hi *node[K, V]) (int, error) {
+		if n == rbt.leaf {
+			return 1, nil
+		}
+		cnt++
+		if cnt > rbt.size {
+			return 0, fmt.Errorf("%w: more nodes than size %d", ErrInvalidTree, rbt.size)
+		}
+		// 子节点为 nil 时不能读取颜色，空子节点应指向 leaf
+		if n.left == nil || n.right == nil {
+			return 0, fmt.Errorf("%w: node %v has nil child", ErrInvalidTree, n.key)
+		}
+		if (lo != nil && n.key <= lo.key) || (hi != nil && n.key >= hi.key) {
+			return 0, fmt.Errorf("%w: key %v out of order", ErrInvalidTree, n.key)
+		}
+		for _, c := range []*node[K, V]{n.left, n.right} {
+			if c != rbt.leaf && c.parent != n {
+				return 0, fmt.Errorf("%w: child %v of %v has wrong parent", ErrInvalidTree, c.key, n.key)
+			}
+		}
+		if n.color == red && (n.left.color == red || n.right.color == red) {
+			return 0, fmt.Errorf("%w: red node %v has red child", ErrInvalidTree, n.key)
+		}
+		lb, err := walk(n.left, lo, n)
+		if err != nil {
+			return 0, err
+		}
+		rb, err := walk(n.right, n, hi)
+		if err != nil {
+			return 0, err
+		}
+		if lb != rb {
+			return 0, fmt.Errorf("%w: black height of %v not equal (%d, %d)", ErrInvalidTree, n.key, lb, rb)
+		}
+		if n.color == black {
+			lb++
+		}
+		return lb, nil
+	}
+	if _, err := walk(rbt.root, nil, nil); err != nil {
+		return err
+	}
+	if cnt != rbt.size {
+		return fmt.Errorf("%w: size should %d, but get %d", ErrInvalidTree, cnt, rbt.size)
+	}
+	return nil
+}
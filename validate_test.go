@@ -0,0 +1,64 @@
+package rbtree
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRBTreeValidate(t *testing.T) {
+	var empty RBTree[int, int]
+	if err := empty.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	newTree := func() *RBTree[int, int] {
+		rbt := NewRBTree[int, int]()
+		for i := 0; i < 10; i++ {
+			rbt.Put(i, i)
+		}
+		if err := rbt.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		return rbt
+	}
+	corrupt := map[string]func(rbt *RBTree[int, int]){
+		"red root": func(rbt *RBTree[int, int]) {
+			rbt.root.color = red
+		},
+		"red child": func(rbt *RBTree[int, int]) {
+			// 8 是红色节点 7 的子节点
+			_, n := rbt.search(8)
+			n.color = red
+		},
+		"black height": func(rbt *RBTree[int, int]) {
+			_, n := rbt.search(0)
+			n.color = red
+		},
+		"order": func(rbt *RBTree[int, int]) {
+			_, n := rbt.search(2)
+			n.key = 100
+		},
+		"parent": func(rbt *RBTree[int, int]) {
+			_, n := rbt.search(0)
+			n.parent = rbt.root
+		},
+		"nil child": func(rbt *RBTree[int, int]) {
+			// 7 是红色节点
+			_, n := rbt.search(7)
+			n.left = nil
+		},
+		"nil root child": func(rbt *RBTree[int, int]) {
+			rbt.root.right = nil
+		},
+		"size": func(rbt *RBTree[int, int]) {
+			rbt.size++
+		},
+	}
+	for name, fn := range corrupt {
+		rbt := newTree()
+		fn(rbt)
+		if err := rbt.Validate(); !errors.Is(err, ErrInvalidTree) {
+			t.Fatalf("error: validate %v should fail, but get %v", name, err)
+		}
+	}
+}
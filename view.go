@@ -0,0 +1,179 @@
+package rbtree
+
+import "golang.org/x/exp/constraints"
+
+// View 是红黑树中一段 key 范围的视图，与原来的红黑树共享数据
+// 范围与 Java 的 NavigableMap 一致：Sub 为 [lo, hi)，Head 为 (-∞, hi)，Tail 为 [lo, +∞)
+// 读取和遍历只返回范围内的 key，写入范围外的 key 会被拒绝
+type View[K constraints.Ordered, V any] struct {
+	tree         *RBTree[K, V]
+	lo, hi       K
+	hasLo, hasHi bool
+}
+
+// Sub 返回 key 在 [lo, hi) 范围内的视图
+func (rbt *RBTree[K, V]) Sub(lo, hi K) *View[K, V] {
+	return &View[K, V]{tree: rbt, lo: lo, hi: hi, hasLo: true, hasHi: true}
+}
+
+// Head 返回 key 小于 hi 的视图
+func (rbt *RBTree[K, V]) Head(hi K) *View[K, V] {
+	return &View[K, V]{tree: rbt, hi: hi, hasHi: true}
+}
+
+// Tail 返回 key 大于等于 lo 的视图
+func (rbt *RBTree[K, V]) Tail(lo K) *View[K, V] {
+	return &View[K, V]{tree: rbt, lo: lo, hasLo: true}
+}
+
+// Sub 返回视图范围与 [lo, hi) 的交集
+func (v *View[K, V]) Sub(lo, hi K) *View[K, V] {
+	return v.Tail(lo).Head(hi)
+}
+
+// Head 返回视图范围与 (-∞, hi) 的交集
+func (v *View[K, V]) Head(hi K) *View[K, V] {
+	res := *v
+	if !res.hasHi || hi < res.hi {
+		res.hi, res.hasHi = hi, true
+	}
+	return &res
+}
+
+// Tail 返回视图范围与 [lo, +∞) 的交集
+func (v *View[K, V]) Tail(lo K) *View[K, V] {
+	res := *v
+	if !res.hasLo || lo > res.lo {
+		res.lo, res.hasLo = lo, true
+	}
+	return &res
+}
+
+func (v *View[K, V]) InRange(key K) bool {
+	return (!v.hasLo || key >= v.lo) && (!v.hasHi || key < v.hi)
+}
+
+func (v *View[K, V]) Get(key K) (value V, ok bool) {
+	if !v.InRange(key) {
+		return value, false
+	}
+	return v.tree.Get(key)
+}
+
+// Put 写入 key，key 不在范围内时返回 false
+func (v *View[K, V]) Put(key K, value V) bool {
+	if !v.InRange(key) {
+		return false
+	}
+	v.tree.Put(key, value)
+	return true
+}
+
+// Remove 删除 key，key 不在范围内时不会删除
+func (v *View[K, V]) Remove(key K) bool {
+	if !v.InRange(key) {
+		return false
+	}
+	return v.tree.Remove(key)
+}
+
+// 范围内最小的节点，调用方需持有读锁
+func (v *View[K, V]) first() *node[K, V] {
+	var n *node[K, V]
+	if v.hasLo {
+		n = v.tree.ceiling(v.lo)
+	} else {
+		n = v.tree.first()
+	}
+	if n == nil || !v.InRange(n.key) {
+		return nil
+	}
+	return n
+}
+
+// 范围内最大的节点，调用方需持有读锁
+func (v *View[K, V]) last() *node[K, V] {
+	var n *node[K, V]
+	if v.hasHi {
+		n = v.tree.floor(v.hi)
+		if n != nil && n.key == v.hi {
+			n = v.tree.precursor(n)
+		}
+	} else {
+		n = v.tree.last()
+	}
+	if n == nil || !v.InRange(n.key) {
+		return nil
+	}
+	return n
+}
+
+// 调用方需持有读锁
+func (v *View[K, V]) walk(fn func(n *node[K, V]) bool) {
+	for n := v.first(); n != nil && v.InRange(n.key); n = v.tree.successor(n) {
+		if !fn(n) {
+			return
+		}
+	}
+}
+
+// Len 返回范围内 key 的个数，需要遍历范围内的所有节点
+func (v *View[K, V]) Len() int {
+	v.tree.mu.RLock()
+	defer v.tree.mu.RUnlock()
+	cnt := 0
+	v.walk(func(*node[K, V]) bool {
+		cnt++
+		return true
+	})
+	return cnt
+}
+
+// Ascend 按 key 升序遍历范围内的节点，fn 返回 false 时停止
+func (v *View[K, V]) Ascend(fn func(key K, value V) bool) {
+	v.tree.mu.RLock()
+	defer v.tree.mu.RUnlock()
+	v.walk(func(n *node[K, V]) bool {
+		return fn(n.key, n.value)
+	})
+}
+
+func (v *View[K, V]) Min() (K, V, bool) {
+	v.tree.mu.RLock()
+	defer v.tree.mu.RUnlock()
+	return v.tree.entry(v.first())
+}
+
+func (v *View[K, V]) Max() (K, V, bool) {
+	v.tree.mu.RLock()
+	defer v.tree.mu.RUnlock()
+	return v.tree.entry(v.last())
+}
+
+// Floor 返回范围内小于等于 key 的最大 key
+func (v *View[K, V]) Floor(key K) (K, V, bool) {
+	if v.hasHi && key >= v.hi {
+		return v.Max()
+	}
+	v.tree.mu.RLock()
+	defer v.tree.mu.RUnlock()
+	n := v.tree.floor(key)
+	if n != nil && !v.InRange(n.key) {
+		n = nil
+	}
+	return v.tree.entry(n)
+}
+
+// Ceiling 返回范围内大于等于 key 的最小 key
+func (v *View[K, V]) Ceiling(key K) (K, V, bool) {
+	if v.hasLo && key < v.lo {
+		return v.Min()
+	}
+	v.tree.mu.RLock()
+	defer v.tree.mu.RUnlock()
+	n := v.tree.ceiling(key)
+	if n != nil && !v.InRange(n.key) {
+		n = nil
+	}
+	return v.tree.entry(n)
+}
@@ -0,0 +1,92 @@
+package rbtree
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/exp/constraints"
+)
+
+func viewKeys[K constraints.Ordered, V any](v *View[K, V]) []K {
+	var keys []K
+	v.Ascend(func(k K, _ V) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+func TestRBTreeView(t *testing.T) {
+	rbt := NewRBTree[int, string]()
+	for i := 0; i < 10; i++ {
+		rbt.Put(i*10, fmt.Sprint(i))
+	}
+	sub := rbt.Sub(20, 60)
+	if got := fmt.Sprint(viewKeys(sub)); got != "[20 30 40 50]" {
+		t.Fatalf("error: sub keys should [20 30 40 50], but get %v", got)
+	}
+	if got := fmt.Sprint(viewKeys(rbt.Head(30))); got != "[0 10 20]" {
+		t.Fatalf("error: head keys should [0 10 20], but get %v", got)
+	}
+	if got := fmt.Sprint(viewKeys(rbt.Tail(75))); got != "[80 90]" {
+		t.Fatalf("error: tail keys should [80 90], but get %v", got)
+	}
+	if got := fmt.Sprint(viewKeys(sub.Head(100).Tail(35))); got != "[40 50]" {
+		t.Fatalf("error: nested view keys should [40 50], but get %v", got)
+	}
+
+	if _, ok := sub.Get(60); ok {
+		t.Fatal("error: 60 should out of range")
+	}
+	if sub.Put(60, "x") || sub.Remove(10) {
+		t.Fatal("error: write out of range should rejected")
+	}
+	if !sub.Put(25, "y") || !sub.Remove(40) {
+		t.Fatal("error: write in range should succeed")
+	}
+	// 视图与原来的红黑树共享数据
+	if v, _ := rbt.Get(25); v != "y" {
+		t.Fatalf("error: get 25 should y, but get %v", v)
+	}
+	rbt.Put(55, "z")
+	if sub.Len() != 5 {
+		t.Fatalf("error: sub len should 5, but get %v", sub.Len())
+	}
+
+	if k, _, _ := sub.Min(); k != 20 {
+		t.Fatalf("error: sub min should 20, but get %v", k)
+	}
+	if k, _, _ := sub.Max(); k != 55 {
+		t.Fatalf("error: sub max should 55, but get %v", k)
+	}
+	if k, _, _ := sub.Floor(100); k != 55 {
+		t.Fatalf("error: sub floor 100 should 55, but get %v", k)
+	}
+	if _, _, ok := sub.Floor(15); ok {
+		t.Fatal("error: sub floor 15 should not exist")
+	}
+	if k, _, _ := sub.Ceiling(0); k != 20 {
+		t.Fatalf("error: sub ceiling 0 should 20, but get %v", k)
+	}
+	if _, _, ok := sub.Ceiling(56); ok {
+		t.Fatal("error: sub ceiling 56 should not exist")
+	}
+	if _, _, ok := rbt.Sub(31, 39).Max(); ok {
+		t.Fatal("error: empty view should not have max")
+	}
+}
+
+func TestRBTreeAscendRange(t *testing.T) {
+	rbt := NewRBTree[int, int]()
+	for i := 0; i < 10; i++ {
+		rbt.Put(i, i)
+	}
+	var keys []int
+	rbt.AscendRange(3, 7, func(k, _ int) bool {
+		keys = append(keys, k)
+		return true
+	})
+	if fmt.Sprint(keys) != "[3 4 5 6]" {
+		t.Fatalf("error: ascend range should [3 4 5 6], but get %v", keys)
+	}
+}
@@ -0,0 +1,93 @@
+package rbtree
+
+// 变更通知的类型
+type EventType byte
+
+const (
+	EventPut EventType = iota
+	EventUpdate
+	EventDelete
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventPut:
+		return "put"
+	case EventUpdate:
+		return "update"
+	case EventDelete:
+		return "delete"
+	}
+	return "unknown"
+}
+
+// Event 描述一次对 key 的修改
+// EventPut: Old 为零值; EventDelete: New 为零值
+type Event[K any, V any] struct {
+	Type EventType
+	Key  K
+	Old  V
+	New  V
+}
+
+// 每个 watcher 的缓冲区大小
+const WatchBuffer = 64
+
+type watcher[K any, V any] struct {
+	lo, hi K
+	ch     chan Event[K, V]
+}
+
+// Watch 订阅 [lo, hi] 范围内 key 的变更
+// 事件在持有写锁时以非阻塞方式发送，写操作不会等待 watcher
+// 如果某个 watcher 的缓冲区（WatchBuffer）已满，说明消费太慢，
+// 该 watcher 会被移除并关闭 channel，调用方需要重新读取树的状态后再次 Watch
+// cancel 可以重复调用，调用后 channel 被关闭
+func (rbt *RBTree[K, V]) Watch(lo, hi K) (<-chan Event[K, V], func()) {
+	w := &watcher[K, V]{
+		lo: lo,
+		hi: hi,
+		ch: make(chan Event[K, V], WatchBuffer),
+	}
+	rbt.mu.Lock()
+	if rbt.watchers == nil {
+		rbt.watchers = make(map[*watcher[K, V]]struct{})
+	}
+	rbt.watchers[w] = struct{}{}
+	rbt.mu.Unlock()
+
+	cancel := func() {
+		rbt.mu.Lock()
+		defer rbt.mu.Unlock()
+		rbt.unwatch(w)
+	}
+	return w.ch, cancel
+}
+
+// 调用方需持有写锁
+func (rbt *RBTree[K, V]) unwatch(w *watcher[K, V]) {
+	if _, ok := rbt.watchers[w]; !ok {
+		return
+	}
+	delete(rbt.watchers, w)
+	close(w.ch)
+}
+
+// 调用方需持有写锁
+func (rbt *RBTree[K, V]) notify(typ EventType, key K, old, value V) {
+	if len(rbt.watchers) == 0 {
+		return
+	}
+	ev := Event[K, V]{typ, key, old, value}
+	for w := range rbt.watchers {
+		if key < w.lo || key > w.hi {
+			continue
+		}
+		select {
+		case w.ch <- ev:
+		default:
+			// 缓冲区已满，丢弃该 watcher
+			rbt.unwatch(w)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package rbtree
+
+import "testing"
+
+func TestRBTreeWatch(t *testing.T) {
+	rbt := NewRBTree[int, string]()
+	ch, cancel := rbt.Watch(10, 20)
+	defer cancel()
+
+	rbt.Put(5, "a")
+	rbt.Put(10, "b")
+	rbt.Put(10, "c")
+	rbt.Put(21, "d")
+	rbt.Remove(10)
+	rbt.Remove(5)
+
+	want := []Event[int, string]{
+		{EventPut, 10, "", "b"},
+		{EventUpdate, 10, "b", "c"},
+		{EventDelete, 10, "c", ""},
+	}
+	for _, w := range want {
+		ev := <-ch
+		if ev != w {
+			t.Fatalf("error: event should %v, but get %v", w, ev)
+		}
+	}
+	select {
+	case ev := <-ch:
+		t.Fatalf("error: unexpected event %v", ev)
+	default:
+	}
+}
+
+func TestRBTreeWatchCancel(t *testing.T) {
+	rbt := NewRBTree[int, int]()
+	ch, cancel := rbt.Watch(0, 100)
+	cancel()
+	cancel()
+	rbt.Put(1, 1)
+	if _, ok := <-ch; ok {
+		t.Fatal("error: channel should closed after cancel")
+	}
+	if len(rbt.watchers) != 0 {
+		t.Fatal("error: watcher should removed")
+	}
+}
+
+func TestRBTreeWatchOverflow(t *testing.T) {
+	rbt := NewRBTree[int, int]()
+	ch, cancel := rbt.Watch(0, 1000)
+	defer cancel()
+	for i := 0; i <= WatchBuffer; i++ {
+		rbt.Put(i, i)
+	}
+	for i := 0; i < WatchBuffer; i++ {
+		if ev := <-ch; ev.Key != i {
+			t.Fatalf("error: event key should %v, but get %v", i, ev.Key)
+		}
+	}
+	if _, ok := <-ch; ok {
+		t.Fatal("error: slow watcher should closed")
+	}
+}